@@ -0,0 +1,311 @@
+// Package admin provides an http.Handler for inspecting the proxies registered
+// (see proxyz.RegisterProxy) and controlling the built-in interceptors applied
+// to them at runtime.
+//
+// Endpoints, relative to where the handler is mounted:
+//
+//	GET    /proxies
+//	GET    /proxies/{proxy}
+//	POST   /proxies/{proxy}/methods/{method}/interceptors
+//	DELETE /proxies/{proxy}/methods/{method}/interceptors/{interceptor}
+//
+// The call counts and the accumulated time of the interceptors are only
+// reported if the stats are enabled (see proxyz.EnableInterceptorStats). The
+// calls to a method are counted by enabling proxyz.ActionCount for it.
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/roy2220/proxyz"
+)
+
+// ProxyInfo represents the information of a proxy registered.
+type ProxyInfo struct {
+	Name           string       `json:"name"`
+	UnderlyingType string       `json:"underlying_type"`
+	Methods        []MethodInfo `json:"methods"`
+}
+
+// MethodInfo represents the information of a method of a proxy.
+type MethodInfo struct {
+	Name         string            `json:"name"`
+	Interceptors []InterceptorInfo `json:"interceptors"`
+
+	// CountedCalls is the number of the calls to the method counted by
+	// proxyz.ActionCount, of all the proxies with the same underlying type
+	// (see proxyz.InterceptionCounts).
+	CountedCalls uint64 `json:"counted_calls"`
+}
+
+// InterceptorInfo represents the information of an interceptor applied to the
+// calls to a method (see proxyz.InterceptorInfo).
+type InterceptorInfo struct {
+	Name      string `json:"name"`
+	Priority  int    `json:"priority"`
+	IsGlobal  bool   `json:"is_global"`
+	CallCount uint64 `json:"call_count"`
+
+	// TotalTime is in the syntax of time.Duration.String.
+	TotalTime string `json:"total_time"`
+}
+
+// InterceptorRequest represents a request to enable a built-in interceptor for
+// a method of a proxy.
+type InterceptorRequest struct {
+	// Action is one of proxyz.ActionLog, proxyz.ActionDelay, proxyz.ActionFail
+	// and proxyz.ActionCount.
+	Action string `json:"action"`
+
+	// Delay is the delay, in the syntax of time.ParseDuration, for
+	// proxyz.ActionDelay.
+	Delay string `json:"delay,omitempty"`
+
+	// Error is the error message for proxyz.ActionFail.
+	Error string `json:"error,omitempty"`
+
+	// Duration is how long, in the syntax of time.ParseDuration, the
+	// interceptor stays enabled. If not specified, the interceptor stays
+	// enabled until disabled explicitly.
+	Duration string `json:"duration,omitempty"`
+}
+
+// InterceptorResponse represents the response to an InterceptorRequest.
+type InterceptorResponse struct {
+	// Name is the name of the interceptor enabled, by which it can be
+	// disabled.
+	Name string `json:"name"`
+}
+
+// Options represents options for handlers.
+type Options struct {
+	// LogWriter is the writer which the log lines of proxyz.ActionLog are
+	// written to. If not specified, stderr will be used.
+	LogWriter io.Writer
+}
+
+// Handler serves the admin endpoints.
+type Handler struct {
+	options           Options
+	lastInterceptorID uint64
+	mutex             sync.Mutex
+	timers            map[string]*time.Timer
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+// Init initializes the handler with the given options and returns it.
+func (h *Handler) Init(options Options) *Handler {
+	h.options = options
+	h.timers = make(map[string]*time.Timer)
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
+	pathSegments := strings.Split(strings.Trim(request.URL.Path, "/"), "/")
+
+	if pathSegments[0] != "proxies" {
+		http.NotFound(responseWriter, request)
+		return
+	}
+
+	switch {
+	case len(pathSegments) == 1 && request.Method == http.MethodGet:
+		h.listProxies(responseWriter)
+	case len(pathSegments) == 2 && request.Method == http.MethodGet:
+		h.getProxy(responseWriter, request, pathSegments[1])
+	case len(pathSegments) == 5 && pathSegments[2] == "methods" && pathSegments[4] == "interceptors" &&
+		request.Method == http.MethodPost:
+		h.enableInterceptor(responseWriter, request, pathSegments[1], pathSegments[3])
+	case len(pathSegments) == 6 && pathSegments[2] == "methods" && pathSegments[4] == "interceptors" &&
+		request.Method == http.MethodDelete:
+		h.disableInterceptor(responseWriter, request, pathSegments[1], pathSegments[3], pathSegments[5])
+	default:
+		http.NotFound(responseWriter, request)
+	}
+}
+
+func (h *Handler) listProxies(responseWriter http.ResponseWriter) {
+	registeredProxies := proxyz.ListProxies()
+	proxyInfos := make([]ProxyInfo, len(registeredProxies))
+
+	for i, registeredProxy := range registeredProxies {
+		proxyInfos[i] = describeProxy(registeredProxy.Name, registeredProxy.Proxy)
+	}
+
+	writeJSON(responseWriter, http.StatusOK, proxyInfos)
+}
+
+func (h *Handler) getProxy(responseWriter http.ResponseWriter, request *http.Request, proxyName string) {
+	proxy, ok := proxyz.LookupProxy(proxyName)
+
+	if !ok {
+		http.Error(responseWriter, fmt.Sprintf("admin: proxy not found; proxyName=%q", proxyName), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(responseWriter, http.StatusOK, describeProxy(proxyName, proxy))
+}
+
+func (h *Handler) enableInterceptor(
+	responseWriter http.ResponseWriter,
+	request *http.Request,
+	proxyName string,
+	methodName string,
+) {
+	proxy, methodIndex, ok := findMethod(responseWriter, proxyName, methodName)
+
+	if !ok {
+		return
+	}
+
+	var interceptorRequest InterceptorRequest
+	decoder := json.NewDecoder(request.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&interceptorRequest); err != nil {
+		http.Error(responseWriter, fmt.Sprintf("admin: interceptor request decoding failed: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var duration time.Duration
+
+	if interceptorRequest.Duration != "" {
+		var err error
+		duration, err = time.ParseDuration(interceptorRequest.Duration)
+
+		if err != nil || duration <= 0 {
+			http.Error(responseWriter, fmt.Sprintf("admin: invalid duration; duration=%q", interceptorRequest.Duration), http.StatusBadRequest)
+			return
+		}
+	}
+
+	rule := proxyz.InterceptionRule{
+		Action: interceptorRequest.Action,
+		Delay:  interceptorRequest.Delay,
+		Error:  interceptorRequest.Error,
+	}
+
+	methodCallInterceptor, err := rule.NewMethodCallInterceptor(h.options.LogWriter)
+
+	if err != nil {
+		http.Error(responseWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	interceptorName := "admin." + rule.Action + "." + strconv.FormatUint(atomic.AddUint64(&h.lastInterceptorID, 1), 10)
+	proxy.XxxAddMethodCallInterceptor(methodIndex, methodCallInterceptor, proxyz.InterceptorOptions{Name: interceptorName})
+
+	if duration > 0 {
+		h.mutex.Lock()
+
+		h.timers[interceptorName] = time.AfterFunc(duration, func() {
+			h.mutex.Lock()
+			delete(h.timers, interceptorName)
+			h.mutex.Unlock()
+			proxy.XxxRemoveMethodCallInterceptor(methodIndex, interceptorName)
+		})
+
+		h.mutex.Unlock()
+	}
+
+	writeJSON(responseWriter, http.StatusCreated, InterceptorResponse{Name: interceptorName})
+}
+
+func (h *Handler) disableInterceptor(
+	responseWriter http.ResponseWriter,
+	request *http.Request,
+	proxyName string,
+	methodName string,
+	interceptorName string,
+) {
+	proxy, methodIndex, ok := findMethod(responseWriter, proxyName, methodName)
+
+	if !ok {
+		return
+	}
+
+	h.mutex.Lock()
+
+	if timer, ok := h.timers[interceptorName]; ok {
+		timer.Stop()
+		delete(h.timers, interceptorName)
+	}
+
+	h.mutex.Unlock()
+
+	if !proxy.XxxRemoveMethodCallInterceptor(methodIndex, interceptorName) {
+		http.Error(responseWriter, fmt.Sprintf("admin: interceptor not found; interceptorName=%q", interceptorName), http.StatusNotFound)
+		return
+	}
+
+	responseWriter.WriteHeader(http.StatusNoContent)
+}
+
+func findMethod(responseWriter http.ResponseWriter, proxyName string, methodName string) (proxyz.Proxy, int, bool) {
+	proxy, ok := proxyz.LookupProxy(proxyName)
+
+	if !ok {
+		http.Error(responseWriter, fmt.Sprintf("admin: proxy not found; proxyName=%q", proxyName), http.StatusNotFound)
+		return nil, 0, false
+	}
+
+	for methodIndex, n := 0, proxy.XxxNumberOfMethods(); methodIndex < n; methodIndex++ {
+		if proxy.XxxGetMethodName(methodIndex) == methodName {
+			return proxy, methodIndex, true
+		}
+	}
+
+	http.Error(responseWriter, fmt.Sprintf("admin: method not found; proxyName=%q methodName=%q", proxyName, methodName), http.StatusNotFound)
+	return nil, 0, false
+}
+
+func describeProxy(proxyName string, proxy proxyz.Proxy) ProxyInfo {
+	proxyInfo := ProxyInfo{
+		Name:           proxyName,
+		UnderlyingType: proxy.XxxUnderlyingType(),
+		Methods:        make([]MethodInfo, proxy.XxxNumberOfMethods()),
+	}
+
+	interceptionCounts := proxyz.InterceptionCounts()
+
+	for methodIndex := range proxyInfo.Methods {
+		infos := proxyz.DescribeMethodCallInterceptors(proxy, methodIndex)
+		methodName := proxy.XxxGetMethodName(methodIndex)
+
+		methodInfo := MethodInfo{
+			Name:         methodName,
+			Interceptors: make([]InterceptorInfo, len(infos)),
+			CountedCalls: interceptionCounts[proxyInfo.UnderlyingType+"."+methodName],
+		}
+
+		for i, info := range infos {
+			methodInfo.Interceptors[i] = InterceptorInfo{
+				Name:      info.Name,
+				Priority:  info.Priority,
+				IsGlobal:  info.IsGlobal,
+				CallCount: info.CallCount,
+				TotalTime: info.TotalTime.String(),
+			}
+		}
+
+		proxyInfo.Methods[methodIndex] = methodInfo
+	}
+
+	return proxyInfo
+}
+
+func writeJSON(responseWriter http.ResponseWriter, statusCode int, value interface{}) {
+	responseWriter.Header().Set("Content-Type", "application/json")
+	responseWriter.WriteHeader(statusCode)
+	json.NewEncoder(responseWriter).Encode(value)
+}
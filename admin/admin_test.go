@@ -0,0 +1,121 @@
+package admin_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roy2220/proxyz"
+	"github.com/roy2220/proxyz/admin"
+	"github.com/roy2220/proxyz/internal/testproxy"
+)
+
+func TestHandler(t *testing.T) {
+	proxyz.EnableInterceptorStats(true)
+	defer proxyz.EnableInterceptorStats(false)
+	kv := testproxy.NewKVProxy(testproxy.MapKV{})
+	require.NoError(t, proxyz.RegisterProxy("kv", kv))
+	defer proxyz.UnregisterProxy("kv")
+	var logBuffer bytes.Buffer
+	server := httptest.NewServer(new(admin.Handler).Init(admin.Options{LogWriter: &logBuffer}))
+	defer server.Close()
+
+	getProxyInfo := func() admin.ProxyInfo {
+		response, err := http.Get(server.URL + "/proxies/kv")
+		require.NoError(t, err)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		var proxyInfo admin.ProxyInfo
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&proxyInfo))
+		return proxyInfo
+	}
+
+	enableInterceptor := func(methodName string, interceptorRequest admin.InterceptorRequest) (int, string) {
+		body, err := json.Marshal(interceptorRequest)
+		require.NoError(t, err)
+		response, err := http.Post(server.URL+"/proxies/kv/methods/"+methodName+"/interceptors", "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		defer response.Body.Close()
+		var interceptorResponse admin.InterceptorResponse
+		json.NewDecoder(response.Body).Decode(&interceptorResponse)
+		return response.StatusCode, interceptorResponse.Name
+	}
+
+	response, err := http.Get(server.URL + "/proxies")
+	require.NoError(t, err)
+	var proxyInfos []admin.ProxyInfo
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&proxyInfos))
+	response.Body.Close()
+	require.Len(t, proxyInfos, 1)
+	assert.Equal(t, "kv", proxyInfos[0].Name)
+	assert.Equal(t, "github.com/roy2220/proxyz/internal/testproxy.KV", proxyInfos[0].UnderlyingType)
+	require.Len(t, proxyInfos[0].Methods, kv.XxxNumberOfMethods())
+	assert.Equal(t, "Get", proxyInfos[0].Methods[testproxy.KVProxyGet].Name)
+	assert.Empty(t, proxyInfos[0].Methods[testproxy.KVProxyGet].Interceptors)
+
+	statusCode, name := enableInterceptor("Set", admin.InterceptorRequest{
+		Action:   proxyz.ActionFail,
+		Error:    "boom",
+		Duration: "100ms",
+	})
+	require.Equal(t, http.StatusCreated, statusCode)
+	assert.Equal(t, "admin.fail.1", name)
+	assert.EqualError(t, kv.Set(context.Background(), "foo", "bar"), "boom")
+	interceptors := getProxyInfo().Methods[testproxy.KVProxySet].Interceptors
+	require.Len(t, interceptors, 1)
+	assert.Equal(t, "admin.fail.1", interceptors[0].Name)
+	assert.Equal(t, uint64(1), interceptors[0].CallCount)
+
+	assert.Eventually(t, func() bool {
+		return len(getProxyInfo().Methods[testproxy.KVProxySet].Interceptors) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NoError(t, kv.Set(context.Background(), "foo", "bar"))
+
+	statusCode, name = enableInterceptor("Get", admin.InterceptorRequest{Action: proxyz.ActionLog})
+	require.Equal(t, http.StatusCreated, statusCode)
+	assert.Equal(t, "admin.log.2", name)
+	value, err := kv.Get(context.Background(), "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", value)
+	assert.Contains(t, logBuffer.String(), "method=github.com/roy2220/proxyz/internal/testproxy.KV.Get")
+
+	request, err := http.NewRequest(http.MethodDelete, server.URL+"/proxies/kv/methods/Get/interceptors/"+name, nil)
+	require.NoError(t, err)
+	response, err = http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	assert.Empty(t, getProxyInfo().Methods[testproxy.KVProxyGet].Interceptors)
+
+	response, err = http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	proxyz.ResetInterceptionCounts()
+	statusCode, _ = enableInterceptor("Get", admin.InterceptorRequest{Action: proxyz.ActionCount})
+	require.Equal(t, http.StatusCreated, statusCode)
+	kv.Get(context.Background(), "foo")
+	kv.Get(context.Background(), "foo")
+	assert.Equal(t, uint64(2), getProxyInfo().Methods[testproxy.KVProxyGet].CountedCalls)
+	assert.Zero(t, getProxyInfo().Methods[testproxy.KVProxySet].CountedCalls)
+
+	statusCode, _ = enableInterceptor("Get", admin.InterceptorRequest{Action: "explode"})
+	assert.Equal(t, http.StatusBadRequest, statusCode)
+	statusCode, _ = enableInterceptor("Get", admin.InterceptorRequest{Action: proxyz.ActionCount, Duration: "forever"})
+	assert.Equal(t, http.StatusBadRequest, statusCode)
+	statusCode, _ = enableInterceptor("Unknown", admin.InterceptorRequest{Action: proxyz.ActionCount})
+	assert.Equal(t, http.StatusNotFound, statusCode)
+
+	response, err = http.Get(server.URL + "/proxies/unknown")
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
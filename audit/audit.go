@@ -0,0 +1,266 @@
+// Package audit provides an interceptor writing a tamper-evident audit trail
+// of method calls.
+//
+// Each record carries the hash of the previous one, so modifying, inserting,
+// deleting or reordering records in the middle of the chain is detected by
+// Verify. The chain alone does not detect the records removed from the end,
+// nor a whole chain rewritten by someone able to recompute the hashes. The
+// former is covered by anchoring the last hash (see Auditor.LastHash)
+// externally, e.g. to a separate store, and the latter by keying the hashes
+// (see Options.HashKey) with a secret not available to the writers of the
+// sink. The chain provides no confidentiality of the records.
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/roy2220/proxyz"
+)
+
+// Record represents an audit record of a method call. Each record is chained
+// to the previous one by the hash.
+type Record struct {
+	Sequence uint64    `json:"sequence"`
+	Time     time.Time `json:"time"`
+	Caller   string    `json:"caller"`
+	Method   string    `json:"method"`
+	Args     []string  `json:"args"`
+	Outcome  string    `json:"outcome"`
+	PrevHash string    `json:"prev_hash"`
+	Hash     string    `json:"hash"`
+}
+
+// ComputeHash returns the hash of the record, which covers all the fields
+// except the hash itself. If the given key is not empty, the hash is an
+// HMAC-SHA256 with the key, otherwise a plain SHA-256.
+func (r *Record) ComputeHash(key []byte) string {
+	record := *r
+	record.Hash = ""
+	data, err := json.Marshal(&record)
+
+	if err != nil {
+		panic(err)
+	}
+
+	if len(key) == 0 {
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:])
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+const (
+	// OutcomeOK is the outcome of the method calls succeeded.
+	OutcomeOK = "ok"
+
+	// OutcomeGoexit is the outcome of the method calls during which the
+	// goroutines exited through runtime.Goexit.
+	OutcomeGoexit = "goexit"
+)
+
+// Sink is the interface for writing audit records.
+type Sink interface {
+	// WriteRecord writes the given record. It is called sequentially.
+	WriteRecord(record *Record) error
+}
+
+// Options represents options for auditors.
+type Options struct {
+	// MethodPatterns are the patterns, in the syntax of path.Match, of the
+	// names of the methods to audit, usually the mutating ones. If not
+	// specified, all methods will be audited.
+	MethodPatterns []string
+
+	// CallerGetter gets the caller identity from the context.Context
+	// argument of the method call. If not specified, CallerFromContext
+	// will be used.
+	CallerGetter func(ctx context.Context) string
+
+	// ArgRedactor decides whether the argument at the given index of the
+	// method with the given name should be redacted, in addition to the
+	// arguments marked as sensitive.
+	ArgRedactor func(methodName string, argIndex int) bool
+
+	// HashKey is the secret key of the hashes of the records (see
+	// Record.ComputeHash). If not specified, the hashes will be unkeyed.
+	HashKey []byte
+
+	// LastRecord is the last record written by the sink before, if any, so
+	// that the chain can be continued.
+	LastRecord *Record
+
+	// ErrorHandler handles the errors encountered while writing records.
+	// If not specified, the errors will be logged.
+	ErrorHandler func(err error)
+}
+
+// Auditor writes audit records of method calls through a sink.
+type Auditor struct {
+	sink     Sink
+	options  Options
+	mutex    sync.Mutex
+	sequence uint64
+	prevHash string
+}
+
+// Init initializes the auditor with the given sink and options and returns it.
+func (a *Auditor) Init(sink Sink, options Options) *Auditor {
+	for _, methodPattern := range options.MethodPatterns {
+		if _, err := path.Match(methodPattern, ""); err != nil {
+			panic(errors.New("audit: invalid method pattern: " + methodPattern))
+		}
+	}
+
+	if options.CallerGetter == nil {
+		options.CallerGetter = CallerFromContext
+	}
+
+	if options.ErrorHandler == nil {
+		options.ErrorHandler = func(err error) { log.Print(err) }
+	}
+
+	a.sink = sink
+	a.options = options
+
+	if lastRecord := options.LastRecord; lastRecord != nil {
+		a.sequence = lastRecord.Sequence + 1
+		a.prevHash = lastRecord.Hash
+	}
+
+	return a
+}
+
+// LastHash returns the hash of the last record written, which can be anchored
+// externally to detect the records removed from the end of the chain.
+func (a *Auditor) LastHash() string {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.prevHash
+}
+
+// Audit adds an interceptor to the given proxy to audit the calls to the
+// methods of the proxy.
+func (a *Auditor) Audit(proxy proxyz.Proxy) {
+	underlyingType := proxy.XxxUnderlyingType()
+
+	for methodIndex, n := 0, proxy.XxxNumberOfMethods(); methodIndex < n; methodIndex++ {
+		if a.matchMethod(proxy.XxxGetMethodName(methodIndex)) {
+			proxy.XxxInterceptMethodCall(methodIndex, a.NewMethodCallInterceptor(underlyingType))
+		}
+	}
+}
+
+// NewMethodCallInterceptor returns an interceptor auditing the method calls,
+// the records of which are named after the given underlying type and the
+// methods.
+func (a *Auditor) NewMethodCallInterceptor(underlyingType string) proxyz.MethodCallInterceptor {
+	return func(methodCall proxyz.MethodCall) {
+		record := Record{
+			Method: underlyingType + "." + methodCall.MethodName(),
+			Args:   a.formatArgs(methodCall),
+		}
+
+		if ctx, ok := proxyz.GetContext(methodCall); ok {
+			record.Caller = a.options.CallerGetter(ctx)
+		}
+
+		proxyz.ForwardAndObserve(methodCall, func(outcome proxyz.Outcome, panicValue interface{}) {
+			switch outcome {
+			case proxyz.Returned:
+				if err := proxyz.GetError(methodCall); err != nil {
+					record.Outcome = "error: " + err.Error()
+				} else {
+					record.Outcome = OutcomeOK
+				}
+			case proxyz.Panicked:
+				record.Outcome = fmt.Sprintf("panic: %v", panicValue)
+			default:
+				record.Outcome = OutcomeGoexit
+			}
+
+			a.writeRecord(&record)
+		})
+	}
+}
+
+func (a *Auditor) matchMethod(methodName string) bool {
+	if len(a.options.MethodPatterns) == 0 {
+		return true
+	}
+
+	for _, methodPattern := range a.options.MethodPatterns {
+		if ok, _ := path.Match(methodPattern, methodName); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a *Auditor) formatArgs(methodCall proxyz.MethodCall) []string {
+	allArgs := proxyz.FormatArgs(methodCall)
+	args := allArgs[:0]
+
+	for i, arg := range allArgs {
+		if i == methodCall.ContextArgIndex() {
+			continue
+		}
+
+		if a.options.ArgRedactor != nil && a.options.ArgRedactor(methodCall.MethodName(), i) {
+			arg = Redacted
+		}
+
+		args = append(args, arg)
+	}
+
+	return args
+}
+
+func (a *Auditor) writeRecord(record *Record) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	record.Sequence = a.sequence
+	record.Time = time.Now().UTC()
+	record.PrevHash = a.prevHash
+	record.Hash = record.ComputeHash(a.options.HashKey)
+
+	if err := a.sink.WriteRecord(record); err != nil {
+		a.options.ErrorHandler(fmt.Errorf("audit: record write failed; sequence=%d method=%q: %v",
+			record.Sequence, record.Method, err))
+		return
+	}
+
+	a.sequence++
+	a.prevHash = record.Hash
+}
+
+// Redacted is the placeholder of the redacted arguments.
+const Redacted = proxyz.Redacted
+
+// WithCaller returns a copy of the given context carrying the given caller
+// identity.
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext returns the caller identity carried by the given context,
+// or an empty string if there is none.
+func CallerFromContext(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
+
+type callerKey struct{}
@@ -0,0 +1,78 @@
+package audit_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roy2220/proxyz/audit"
+	"github.com/roy2220/proxyz/internal/testproxy"
+)
+
+func TestAuditor(t *testing.T) {
+	dirPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dirPath)
+	filePath := filepath.Join(dirPath, "audit.jsonl")
+	hashKey := []byte("key")
+	var lastHash string
+
+	for i := 0; i < 2; i++ {
+		var sink audit.FileSink
+		require.NoError(t, sink.Open(filePath))
+		auditor := new(audit.Auditor).Init(&sink, audit.Options{
+			MethodPatterns: []string{"Set"},
+			ArgRedactor: func(methodName string, argIndex int) bool {
+				return methodName == "Set" && argIndex == 2
+			},
+			HashKey:    hashKey,
+			LastRecord: sink.LastRecord(),
+		})
+		kv := testproxy.NewKVProxy(testproxy.MapKV{})
+		auditor.Audit(kv)
+		ctx := audit.WithCaller(context.Background(), "admin")
+		assert.NoError(t, kv.Set(ctx, "foo", "secret"))
+		_, err := kv.Get(ctx, "foo")
+		assert.NoError(t, err)
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.Equal(t, context.Canceled, kv.Set(ctx, "bar", "secret"))
+		require.NoError(t, sink.Close())
+		assert.NotEqual(t, lastHash, auditor.LastHash())
+		lastHash = auditor.LastHash()
+	}
+
+	require.NoError(t, audit.VerifyFile(filePath, hashKey))
+	assert.Error(t, audit.VerifyFile(filePath, nil))
+	assert.Error(t, audit.VerifyFile(filePath, []byte("other key")))
+	data, err := ioutil.ReadFile(filePath)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.Len(t, lines, 4)
+	assert.Contains(t, lines[0], `"sequence":0`)
+	assert.Contains(t, lines[0], `"caller":"admin"`)
+	assert.Contains(t, lines[0], `"method":"github.com/roy2220/proxyz/internal/testproxy.KV.Set"`)
+	assert.Contains(t, lines[0], `"args":["foo","[REDACTED]"]`)
+	assert.Contains(t, lines[0], `"outcome":"ok"`)
+	assert.Contains(t, lines[1], `"outcome":"error: context canceled"`)
+	assert.Contains(t, lines[3], `"sequence":3`)
+	assert.NotContains(t, string(data), "secret")
+	assert.Contains(t, lines[3], `"hash":"`+lastHash+`"`)
+
+	lines[2] = strings.Replace(lines[2], `"foo"`, `"baz"`, 1)
+	err = audit.Verify(strings.NewReader(strings.Join(lines, "\n")), hashKey)
+	if assert.IsType(t, (*audit.VerificationError)(nil), err) {
+		assert.Equal(t, 3, err.(*audit.VerificationError).LineNumber)
+	}
+
+	err = audit.Verify(strings.NewReader(strings.Join(append(lines[:1], lines[2:]...), "\n")), hashKey)
+	if assert.IsType(t, (*audit.VerificationError)(nil), err) {
+		assert.Equal(t, 2, err.(*audit.VerificationError).LineNumber)
+	}
+}
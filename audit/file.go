@@ -0,0 +1,155 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// FileSink is a Sink appending the records to a file in the format of JSON
+// lines.
+type FileSink struct {
+	file       *os.File
+	lastRecord *Record
+}
+
+var _ = (Sink)((*FileSink)(nil))
+
+// Open opens the file with the given path for appending records, the file
+// will be created if it does not exist.
+func (fs *FileSink) Open(filePath string) error {
+	file, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+
+	if err != nil {
+		return fmt.Errorf("audit: file open failed; filePath=%q: %v", filePath, err)
+	}
+
+	lastRecord, err := readLastRecord(file)
+
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("audit: file read failed; filePath=%q: %v", filePath, err)
+	}
+
+	fs.file = file
+	fs.lastRecord = lastRecord
+	return nil
+}
+
+// LastRecord returns the last record in the file when it was opened, if any,
+// which should be passed to Options.LastRecord to continue the chain.
+func (fs *FileSink) LastRecord() *Record {
+	return fs.lastRecord
+}
+
+// WriteRecord implements Sink.WriteRecord.
+func (fs *FileSink) WriteRecord(record *Record) error {
+	data, err := json.Marshal(record)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fs.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the file.
+func (fs *FileSink) Close() error {
+	return fs.file.Close()
+}
+
+// VerificationError is returned by Verify when the chain of records is
+// broken.
+type VerificationError struct {
+	LineNumber int
+	Reason     string
+}
+
+// Error implements error.Error.
+func (ve *VerificationError) Error() string {
+	return fmt.Sprintf("audit: verification failed; lineNumber=%d: %s", ve.LineNumber, ve.Reason)
+}
+
+// Verify checks the chain of records read from the given reader, in the
+// format of JSON lines, with the given key of the hashes (see
+// Options.HashKey). It returns a *VerificationError if the chain is broken.
+func Verify(reader io.Reader, hashKey []byte) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, 1<<26)
+	var prevRecord *Record
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		var record Record
+
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return &VerificationError{lineNumber, fmt.Sprintf("malformed record: %v", err)}
+		}
+
+		if prevRecord != nil {
+			if record.Sequence != prevRecord.Sequence+1 {
+				return &VerificationError{lineNumber, fmt.Sprintf("sequence mismatch; expected=%d actual=%d",
+					prevRecord.Sequence+1, record.Sequence)}
+			}
+
+			if record.PrevHash != prevRecord.Hash {
+				return &VerificationError{lineNumber, "previous hash mismatch"}
+			}
+		}
+
+		if record.Hash != record.ComputeHash(hashKey) {
+			return &VerificationError{lineNumber, "hash mismatch"}
+		}
+
+		prevRecord = &record
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("audit: read failed: %v", err)
+	}
+
+	return nil
+}
+
+// VerifyFile checks the chain of records in the file with the given path, with
+// the given key of the hashes. See Verify.
+func VerifyFile(filePath string, hashKey []byte) error {
+	file, err := os.Open(filePath)
+
+	if err != nil {
+		return fmt.Errorf("audit: file open failed; filePath=%q: %v", filePath, err)
+	}
+
+	defer file.Close()
+	return Verify(file, hashKey)
+}
+
+func readLastRecord(file *os.File) (*Record, error) {
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<26)
+	var lastLine []byte
+
+	for scanner.Scan() {
+		if line := scanner.Bytes(); len(line) >= 1 {
+			lastLine = append(lastLine[:0], line...)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if lastLine == nil {
+		return nil, nil
+	}
+
+	var lastRecord Record
+
+	if err := json.Unmarshal(lastLine, &lastRecord); err != nil {
+		return nil, err
+	}
+
+	return &lastRecord, nil
+}
@@ -0,0 +1,228 @@
+// Package authz provides an interceptor authorizing method calls against a
+// declarative policy, with the caller identities carried by the
+// context.Context arguments.
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/roy2220/proxyz"
+)
+
+// Identity represents the identity of a caller.
+type Identity struct {
+	Name  string
+	Roles []string
+}
+
+// HasRole returns whether the identity has the given role.
+func (i *Identity) HasRole(role string) bool {
+	for _, role2 := range i.Roles {
+		if role2 == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithIdentity returns a copy of the given context carrying the given identity.
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the identity carried by the given context, or
+// nil if there is none.
+func IdentityFromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
+
+type identityKey struct{}
+
+// Predicate is the type of function deciding whether the caller with the
+// given identity, which is never nil, is allowed to make the method call.
+type Predicate func(identity *Identity, methodCall proxyz.MethodCall) bool
+
+// Decision represents an authorization decision.
+type Decision struct {
+	Time       time.Time
+	MethodName string
+
+	// Identity is the identity of the caller, or nil if the caller is
+	// anonymous.
+	Identity *Identity
+	Allowed  bool
+	Reason   string
+}
+
+// DeniedError is the error returned by the method calls denied.
+type DeniedError struct {
+	MethodName string
+	Caller     string
+	Reason     string
+}
+
+// Error implements error.Error.
+func (de *DeniedError) Error() string {
+	return fmt.Sprintf("authz: permission denied; methodName=%q caller=%q: %s", de.MethodName, de.Caller, de.Reason)
+}
+
+// Options represents options for authorizers.
+type Options struct {
+	// Predicates are the predicates which the rules refer to by names.
+	Predicates map[string]Predicate
+
+	// DenyHook, if specified, is called with every deny decision, e.g. for
+	// auditing.
+	DenyHook func(decision *Decision)
+}
+
+// Authorizer authorizes method calls against a policy.
+type Authorizer struct {
+	options Options
+	policy  atomic.Value
+}
+
+// Init initializes the authorizer with the given policy and options and
+// returns it.
+func (a *Authorizer) Init(policy *Policy, options Options) (*Authorizer, error) {
+	a.options = options
+
+	if err := a.SetPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// SetPolicy replaces the policy at runtime. On failure, the policy is
+// unchanged.
+func (a *Authorizer) SetPolicy(policy *Policy) error {
+	if policy == nil {
+		return errors.New("authz: nil policy")
+	}
+
+	predicateNames := make(map[string]struct{}, len(a.options.Predicates))
+
+	for predicateName := range a.options.Predicates {
+		predicateNames[predicateName] = struct{}{}
+	}
+
+	if err := policy.Validate(predicateNames); err != nil {
+		return err
+	}
+
+	a.policy.Store(policy)
+	return nil
+}
+
+// ReloadPolicyFile reads a policy from the file with the given path and
+// replaces the policy with it at runtime. On failure, the policy is unchanged.
+func (a *Authorizer) ReloadPolicyFile(filePath string) error {
+	policy, err := ReadPolicyFile(filePath)
+
+	if err != nil {
+		return err
+	}
+
+	return a.SetPolicy(policy)
+}
+
+// Policy returns the policy in use.
+func (a *Authorizer) Policy() *Policy {
+	return a.policy.Load().(*Policy)
+}
+
+// Authorize decides whether the method call is allowed.
+func (a *Authorizer) Authorize(methodCall proxyz.MethodCall) *Decision {
+	decision := Decision{
+		Time:       time.Now(),
+		MethodName: methodCall.MethodName(),
+	}
+
+	if ctx, ok := proxyz.GetContext(methodCall); ok {
+		decision.Identity = IdentityFromContext(ctx)
+	}
+
+	decision.Allowed, decision.Reason = a.authorize(methodCall, decision.Identity)
+	return &decision
+}
+
+func (a *Authorizer) authorize(methodCall proxyz.MethodCall, identity *Identity) (bool, string) {
+	policy := a.Policy()
+	rule, ok := policy.findRule(methodCall.MethodName())
+
+	if !ok {
+		if policy.DefaultAllow {
+			return true, "allowed by default"
+		}
+
+		return false, "no rule matched"
+	}
+
+	if rule.AllowAnonymous {
+		return true, "anonymous callers allowed"
+	}
+
+	if identity == nil {
+		return false, "no identity"
+	}
+
+	if len(rule.Roles) >= 1 {
+		ok := false
+
+		for _, role := range rule.Roles {
+			if identity.HasRole(role) {
+				ok = true
+				break
+			}
+		}
+
+		if !ok {
+			return false, fmt.Sprintf("none of the roles %q", rule.Roles)
+		}
+	}
+
+	for _, predicateName := range rule.Predicates {
+		if !a.options.Predicates[predicateName](identity, methodCall) {
+			return false, fmt.Sprintf("predicate %q not satisfied", predicateName)
+		}
+	}
+
+	return true, "rule matched"
+}
+
+// InterceptMethodCall is a proxyz.MethodCallInterceptor authorizing the
+// method call. If denied, a *DeniedError is set as the trailing error result
+// and the method call will NOT be forwarded. If the method has no trailing
+// error result, it panics with the *DeniedError instead.
+func (a *Authorizer) InterceptMethodCall(methodCall proxyz.MethodCall) {
+	decision := a.Authorize(methodCall)
+
+	if decision.Allowed {
+		methodCall.Forward()
+		return
+	}
+
+	if a.options.DenyHook != nil {
+		a.options.DenyHook(decision)
+	}
+
+	err := &DeniedError{
+		MethodName: decision.MethodName,
+		Reason:     decision.Reason,
+	}
+
+	if decision.Identity != nil {
+		err.Caller = decision.Identity.Name
+	}
+
+	if !proxyz.SetError(methodCall, err) {
+		panic(err)
+	}
+}
@@ -0,0 +1,97 @@
+package authz_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roy2220/proxyz"
+	"github.com/roy2220/proxyz/authz"
+	"github.com/roy2220/proxyz/internal/testproxy"
+)
+
+func TestAuthorizer(t *testing.T) {
+	policy, err := authz.ReadPolicy(strings.NewReader(`{
+		"rules": [
+			{"methods": ["Set"], "roles": ["admin"], "predicates": ["notReserved"]},
+			{"methods": ["Get"]},
+			{"methods": ["Keys"], "allow_anonymous": true}
+		]
+	}`))
+	require.NoError(t, err)
+	var denials []*authz.Decision
+	authorizer, err := new(authz.Authorizer).Init(policy, authz.Options{
+		Predicates: map[string]authz.Predicate{
+			"notReserved": func(_ *authz.Identity, methodCall proxyz.MethodCall) bool {
+				return !strings.HasPrefix(methodCall.GetArg(1).(string), "_")
+			},
+		},
+		DenyHook: func(decision *authz.Decision) { denials = append(denials, decision) },
+	})
+	require.NoError(t, err)
+	kv := testproxy.NewKVProxy(testproxy.MapKV{})
+	proxyz.InterceptAllMethodCalls(kv, authorizer.InterceptMethodCall)
+	admin := authz.WithIdentity(context.Background(), &authz.Identity{Name: "roy", Roles: []string{"admin"}})
+	user := authz.WithIdentity(context.Background(), &authz.Identity{Name: "bob", Roles: []string{"user"}})
+
+	assert.NoError(t, kv.Set(admin, "foo", "bar"))
+	err = kv.Set(admin, "_foo", "bar")
+	assert.EqualError(t, err, `authz: permission denied; methodName="Set" caller="roy": predicate "notReserved" not satisfied`)
+	err = kv.Set(user, "foo", "bar")
+	if assert.IsType(t, (*authz.DeniedError)(nil), err) {
+		assert.Equal(t, "bob", err.(*authz.DeniedError).Caller)
+	}
+	_, err = kv.Get(user, "foo")
+	assert.NoError(t, err)
+	_, err = kv.Get(context.Background(), "foo")
+	assert.IsType(t, (*authz.DeniedError)(nil), err)
+	assert.Equal(t, []string{"foo"}, kv.Keys())
+
+	require.Len(t, denials, 3)
+	assert.Equal(t, "Set", denials[0].MethodName)
+	assert.False(t, denials[0].Allowed)
+	assert.Equal(t, "bob", denials[1].Identity.Name)
+	assert.Nil(t, denials[2].Identity)
+	assert.Equal(t, "no identity", denials[2].Reason)
+}
+
+func TestAuthorizerReload(t *testing.T) {
+	dirPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dirPath)
+	filePath := filepath.Join(dirPath, "policy.json")
+	require.NoError(t, ioutil.WriteFile(filePath, []byte(`{"rules": [{"methods": ["*"], "roles": ["admin"]}]}`), 0644))
+	policy, err := authz.ReadPolicyFile(filePath)
+	require.NoError(t, err)
+	authorizer, err := new(authz.Authorizer).Init(policy, authz.Options{})
+	require.NoError(t, err)
+	kv := testproxy.NewKVProxy(testproxy.MapKV{})
+	proxyz.InterceptAllMethodCalls(kv, authorizer.InterceptMethodCall)
+	user := authz.WithIdentity(context.Background(), &authz.Identity{Name: "bob", Roles: []string{"user"}})
+
+	assert.Error(t, kv.Set(user, "foo", "bar"))
+	assert.Panics(t, func() { kv.Keys() })
+
+	require.NoError(t, ioutil.WriteFile(filePath, []byte(`{"rules": [{"methods": ["*"], "roles": ["admin", "user"]}]}`), 0644))
+	require.NoError(t, authorizer.ReloadPolicyFile(filePath))
+	assert.NoError(t, kv.Set(user, "foo", "bar"))
+
+	require.NoError(t, ioutil.WriteFile(filePath, []byte(`{"rules": [{"methods": ["["]}]}`), 0644))
+	assert.Error(t, authorizer.ReloadPolicyFile(filePath))
+	require.NoError(t, ioutil.WriteFile(filePath, []byte(`{"rules": [{"methods": ["*"], "predicates": ["x"]}]}`), 0644))
+	assert.Error(t, authorizer.ReloadPolicyFile(filePath))
+	require.NoError(t, ioutil.WriteFile(filePath, []byte(`{"default_allow": true, "rules": []}`), 0644))
+	require.NoError(t, authorizer.ReloadPolicyFile(filePath))
+	assert.NotPanics(t, func() { kv.Keys() })
+
+	assert.Error(t, authorizer.SetPolicy(nil))
+	assert.NotPanics(t, func() { kv.Keys() })
+	_, err = new(authz.Authorizer).Init(nil, authz.Options{})
+	assert.Error(t, err)
+}
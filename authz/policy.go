@@ -0,0 +1,100 @@
+package authz
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+)
+
+// Policy represents an access-control policy. The first rule matching the
+// method decides whether a method call is allowed. If no rule matches, the
+// method call is denied, unless DefaultAllow is set.
+type Policy struct {
+	Rules        []Rule `json:"rules"`
+	DefaultAllow bool   `json:"default_allow,omitempty"`
+}
+
+// Rule represents a rule of a policy.
+type Rule struct {
+	// MethodPatterns are the patterns, in the syntax of path.Match, of the
+	// names of the methods the rule applies to.
+	MethodPatterns []string `json:"methods"`
+
+	// Roles lists the roles allowed, the caller should have at least one of
+	// them. An empty list means any caller with an identity.
+	Roles []string `json:"roles,omitempty"`
+
+	// Predicates lists the names of the predicates, registered through
+	// Options.Predicates, which should all be satisfied.
+	Predicates []string `json:"predicates,omitempty"`
+
+	// AllowAnonymous indicates whether the rule allows anonymous callers, i.e. the
+	// ones without identities. Roles and Predicates are ignored if set.
+	AllowAnonymous bool `json:"allow_anonymous,omitempty"`
+}
+
+// Validate checks the policy, the names of the known predicates are given.
+func (p *Policy) Validate(predicateNames map[string]struct{}) error {
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+
+		if len(rule.MethodPatterns) == 0 {
+			return fmt.Errorf("authz: no method patterns; ruleIndex=%d", i)
+		}
+
+		for _, methodPattern := range rule.MethodPatterns {
+			if _, err := path.Match(methodPattern, ""); err != nil {
+				return fmt.Errorf("authz: invalid method pattern; ruleIndex=%d methodPattern=%q", i, methodPattern)
+			}
+		}
+
+		for _, predicateName := range rule.Predicates {
+			if _, ok := predicateNames[predicateName]; !ok {
+				return fmt.Errorf("authz: unknown predicate; ruleIndex=%d predicateName=%q", i, predicateName)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *Policy) findRule(methodName string) (*Rule, bool) {
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+
+		for _, methodPattern := range rule.MethodPatterns {
+			if ok, _ := path.Match(methodPattern, methodName); ok {
+				return rule, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// ReadPolicy reads a policy in JSON from the given reader.
+func ReadPolicy(reader io.Reader) (*Policy, error) {
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+	var policy Policy
+
+	if err := decoder.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("authz: policy decode failed: %v", err)
+	}
+
+	return &policy, nil
+}
+
+// ReadPolicyFile reads a policy in JSON from the file with the given path.
+func ReadPolicyFile(filePath string) (*Policy, error) {
+	file, err := os.Open(filePath)
+
+	if err != nil {
+		return nil, fmt.Errorf("authz: file open failed; filePath=%q: %v", filePath, err)
+	}
+
+	defer file.Close()
+	return ReadPolicy(file)
+}
@@ -6,7 +6,9 @@ import (
 	"go/ast"
 	"go/printer"
 	"go/token"
+	"path"
 	"strings"
+	"unicode"
 )
 
 // MethodSet ...
@@ -227,6 +229,10 @@ func (ms *MethodSet) parseInterfaceType(context *ParseContext, currentPackagePat
 				return err
 			}
 
+			if err := method2.parseDoc(method1.Doc); err != nil {
+				return err
+			}
+
 			ms.addMethod(method2)
 		}
 	}
@@ -335,6 +341,10 @@ func (ms *MethodSet) parseFuncDecls(context *ParseContext, package1 *package1, o
 				return err
 			}
 
+			if err := method.parseDoc(funcDecl.Doc); err != nil {
+				return err
+			}
+
 			ms.addMethod(method)
 		}
 	}
@@ -370,11 +380,12 @@ func (ms *MethodSet) addMethod(method1 Method) {
 
 // Method ...
 type Method struct {
-	Name        string
-	ArgNames    []string
-	ArgTypes    []Type
-	ResultTypes []Type
-	IsVariadic  bool
+	Name             string
+	ArgNames         []string
+	ArgTypes         []Type
+	ResultTypes      []Type
+	IsVariadic       bool
+	RedactedArgNames []string
 }
 
 func (m *Method) parseFuncType(context *ParseContext, name string, funcType *ast.FuncType) error {
@@ -425,6 +436,112 @@ func (m *Method) parseFuncType(context *ParseContext, name string, funcType *ast
 	return nil
 }
 
+func (m *Method) parseDoc(doc *ast.CommentGroup) error {
+	if doc == nil {
+		return nil
+	}
+
+	for _, comment := range doc.List {
+		if !strings.HasPrefix(comment.Text, redactDirective) {
+			continue
+		}
+
+		rest := comment.Text[len(redactDirective):]
+
+		if rest != "" && !unicode.IsSpace(rune(rest[0])) {
+			// another directive, e.g. "//proxyz:redactx"
+			continue
+		}
+
+		argNames := strings.Fields(rest)
+
+		if len(argNames) == 0 {
+			return fmt.Errorf("methodset: no arg name in directive; methodName=%q directive=%q",
+				m.Name, comment.Text)
+		}
+
+		for _, argName := range argNames {
+			if !m.hasArg(argName) {
+				return fmt.Errorf("methodset: unknown arg name in directive; methodName=%q argName=%q",
+					m.Name, argName)
+			}
+		}
+
+		m.RedactedArgNames = append(m.RedactedArgNames, argNames...)
+	}
+
+	return nil
+}
+
+func (m *Method) hasArg(argName string) bool {
+	for _, argName2 := range m.ArgNames {
+		if argName2 == argName {
+			return true
+		}
+	}
+
+	return false
+}
+
+const redactDirective = "//proxyz:redact"
+
+// SensitiveArgIndexes ...
+func (m *Method) SensitiveArgIndexes(argNamePatterns []string) []int {
+	var argIndexes []int
+
+	for i, argName := range m.ArgNames {
+		if argName == "_" || m.ContextArgIndex() == i {
+			continue
+		}
+
+		if argNameIsRedacted(argName, m.RedactedArgNames, argNamePatterns) {
+			argIndexes = append(argIndexes, i)
+		}
+	}
+
+	return argIndexes
+}
+
+func argNameIsRedacted(argName string, redactedArgNames []string, argNamePatterns []string) bool {
+	for _, redactedArgName := range redactedArgNames {
+		if redactedArgName == argName {
+			return true
+		}
+	}
+
+	for _, argNamePattern := range argNamePatterns {
+		if ok, _ := path.Match(strings.ToLower(argNamePattern), strings.ToLower(argName)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ContextArgIndex ...
+func (m *Method) ContextArgIndex() int {
+	for i := range m.ArgTypes {
+		if m.IsVariadic && i == len(m.ArgTypes)-1 {
+			break
+		}
+
+		if m.ArgTypes[i].IsContext() {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// ErrorResultIndex ...
+func (m *Method) ErrorResultIndex() int {
+	if i := len(m.ResultTypes) - 1; i >= 0 && m.ResultTypes[i].IsError() {
+		return i
+	}
+
+	return -1
+}
+
 // Type ...
 type Type struct {
 	Format            string
@@ -476,6 +593,16 @@ func (t *Type) parseExpr(context *ParseContext, expr ast.Expr) error {
 	return nil
 }
 
+// IsError ...
+func (t *Type) IsError() bool {
+	return t.Format == "error" && len(t.PackageBasicInfos) == 0
+}
+
+// IsContext ...
+func (t *Type) IsContext() bool {
+	return t.Format == "%sContext" && len(t.PackageBasicInfos) == 1 && t.PackageBasicInfos[0].Path == "context"
+}
+
 type visitorFunc func(ast.Node) ast.Visitor
 
 func (vf visitorFunc) Visit(node ast.Node) ast.Visitor {
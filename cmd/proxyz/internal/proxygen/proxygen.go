@@ -22,6 +22,11 @@ type ProxyGen struct {
 	OutputPackagePattern string
 	OutputTypeName       string
 
+	// RedactArgNamePatterns are the glob patterns, matched case-insensitively,
+	// of the names of the arguments to mark as sensitive, in addition to the
+	// ones listed by the "//proxyz:redact" directives.
+	RedactArgNamePatterns []string
+
 	buffer               bytes.Buffer
 	outputPackageDirPath string
 	outputPackageName    string
@@ -211,6 +216,11 @@ func (p *{{ $.TypeName }}) XxxGetMethodName(methodIndex int) string {
 
 func (p *{{ $.TypeName }}) XxxNumberOfMethods() int { return {{ len $.MethodNames }} }
 func (p *{{ $.TypeName }}) XxxUnderlyingType() string { return "{{ $.UnderlyingTypeRepr }}" }
+
+func (p *{{ $.TypeName }}) XxxIsOfUnderlyingType(value interface{}) bool {
+	_, ok := value.({{ $.UnderlyingType }})
+	return ok
+}
 `
 	methodNames := make([]string, len(orderedMethods))
 
@@ -221,10 +231,12 @@ func (p *{{ $.TypeName }}) XxxUnderlyingType() string { return "{{ $.UnderlyingT
 	data := struct {
 		TypeName           string
 		MethodNames        []string
+		UnderlyingType     string
 		UnderlyingTypeRepr string
 	}{
 		TypeName:           pg.OutputTypeName,
 		MethodNames:        methodNames,
+		UnderlyingType:     pg.formatInputType(),
 		UnderlyingTypeRepr: pg.inputPackagePath() + "." + pg.inputTypeName(),
 	}
 
@@ -244,6 +256,8 @@ func (pg *ProxyGen) emitMethodDeclaration(method methodset.Method, methodIndex i
 const {{ $.TypeName }}{{ $.MethodName }} = {{ $.MethodIndex }}
 
 type {{ $.TypeName }}{{ $.MethodName }}Call struct {
+	proxyz.XxxMethodCallBase
+{{ "" }}
 {{- range $i, $argType := $.ArgTypes }}
 	Arg{{ $i }}{{ " " }}
 	{{- if and $.MethodIsVariadic (last $i $.ArgTypes) }}
@@ -308,12 +322,21 @@ func (mc *{{ $.TypeName }}{{ $.MethodName }}Call) SetArg(argIndex int, arg inter
 	switch argIndex {
 {{- range $i, $argType := $.ArgTypes }}
 	case {{ $i }}:
-		mc.Arg{{ $i }} = arg.(
+		if arg == nil {
+			mc.Arg{{ $i }} = *new(
 	{{- if and $.MethodIsVariadic (last $i $.ArgTypes) }}
 		{{- "[]" }}
 	{{- end }}
 	{{- $argType }}
 	{{- ")"}}
+		} else {
+			mc.Arg{{ $i }} = arg.(
+	{{- if and $.MethodIsVariadic (last $i $.ArgTypes) }}
+		{{- "[]" }}
+	{{- end }}
+	{{- $argType }}
+	{{- ")"}}
+		}
 {{- end }}
 	default:
 		panic("arg index out of range")
@@ -335,17 +358,64 @@ func (mc *{{ $.TypeName }}{{ $.MethodName }}Call) SetResult(resultIndex int, res
 	switch resultIndex {
 {{- range $i, $resultType := $.ResultTypes }}
 	case {{ $i }}:
-		mc.Result{{ $i }} = result.({{ $resultType }})
+		if result == nil {
+			mc.Result{{ $i }} = *new({{ $resultType }})
+		} else {
+			mc.Result{{ $i }} = result.({{ $resultType }})
+		}
 {{- end }}
 	default:
 		panic("result index out of range")
 	}
 }
 
+func (mc *{{ $.TypeName }}{{ $.MethodName }}Call) UnderlyingType() string { return "{{ $.UnderlyingTypeRepr }}" }
 func (mc *{{ $.TypeName }}{{ $.MethodName }}Call) MethodName() string { return "{{ $.MethodName }}" }
 func (mc *{{ $.TypeName }}{{ $.MethodName }}Call) MethodIndex() int { return {{ $.TypeName }}{{ $.MethodName }} }
 func (mc *{{ $.TypeName }}{{ $.MethodName }}Call) NumberOfArgs() int { return {{ len $.ArgTypes }} }
 func (mc *{{ $.TypeName }}{{ $.MethodName }}Call) NumberOfResults() int { return {{ len $.ResultTypes }} }
+func (mc *{{ $.TypeName }}{{ $.MethodName }}Call) ContextArgIndex() int { return {{ $.ContextArgIndex }} }
+func (mc *{{ $.TypeName }}{{ $.MethodName }}Call) ErrorResultIndex() int { return {{ $.ErrorResultIndex }} }
+
+func (mc *{{ $.TypeName }}{{ $.MethodName }}Call) ArgName(argIndex int) string {
+	return [...]string{
+{{- range $i, $argName := $.OriginalArgNames }}
+		{{- if $i }}
+			{{- ", " }}
+		{{- end }}
+		{{- printf "%q" $argName }}
+{{- end -}}
+	}[argIndex]
+}
+
+func (mc *{{ $.TypeName }}{{ $.MethodName }}Call) IsArgSensitive(argIndex int) bool {
+{{- if $.SensitiveArgIndexes }}
+	switch argIndex {
+	case {{ range $i, $argIndex := $.SensitiveArgIndexes }}
+		{{- if $i }}
+			{{- ", " }}
+		{{- end }}
+		{{- $argIndex }}
+	{{- end }}:
+		return true
+	default:
+		return false
+	}
+{{- else }}
+	return false
+{{- end }}
+}
+
+func (mc *{{ $.TypeName }}{{ $.MethodName }}Call) String() string { return proxyz.FormatMethodCall(mc) }
+
+func (mc *{{ $.TypeName }}{{ $.MethodName }}Call) Callee() interface{} { return mc.callee }
+func (mc *{{ $.TypeName }}{{ $.MethodName }}Call) SetCallee(callee interface{}) { mc.callee = callee.({{ $.UnderlyingType }}) }
+
+func (mc *{{ $.TypeName }}{{ $.MethodName }}Call) Clone() proxyz.MethodCall {
+	clone := *mc
+	clone.XxxInitClone(&clone)
+	return &clone
+}
 
 func (mc *{{ $.TypeName }}{{ $.MethodName }}Call) getNextInterceptor() (proxyz.MethodCallInterceptor, bool) {
 	if i := mc.nextInterceptorIndex; i < len(mc.interceptors) {
@@ -384,7 +454,11 @@ func (p *{{ $.TypeName }}) {{ $.MethodName }}(
 	{{- end }}
 {{- end }}
 {{- " {" }}
-	methodCallInterceptors := p.XxxGetMethodCallInterceptors({{ $.TypeName }}{{ $.MethodName }})
+{{- if ge $.ContextArgIndex 0 }}
+	methodCallInterceptors := p.XxxResolveContextMethodCallInterceptors({{ index $.ArgNames $.ContextArgIndex }}, p, {{ $.TypeName }}{{ $.MethodName }})
+{{- else }}
+	methodCallInterceptors := p.XxxResolveMethodCallInterceptors(p, {{ $.TypeName }}{{ $.MethodName }})
+{{- end }}
 
 	if len(methodCallInterceptors) == 0 {
 		{{ "" }}
@@ -415,6 +489,7 @@ func (p *{{ $.TypeName }}) {{ $.MethodName }}(
 		interceptors: methodCallInterceptors,
 	}
 
+	methodCall.XxxInit(&methodCall)
 	methodCall.Forward()
 {{- if $.ResultTypes }}
 	{{ "return " }}
@@ -428,12 +503,14 @@ func (p *{{ $.TypeName }}) {{ $.MethodName }}(
 }
 `
 
+	originalArgNames := make([]string, len(method.ArgTypes))
+	copy(originalArgNames, method.ArgNames)
 	argNames := make([]string, len(method.ArgTypes))
-	copy(argNames, method.ArgNames)
 
-	for i, argName := range argNames {
+	for i, argName := range originalArgNames {
 		switch argName {
 		case "", "_":
+			originalArgNames[i] = ""
 			argNames[i] = "arg" + strconv.Itoa(i)
 		default:
 			argNames[i] = "_" + argName + "_"
@@ -453,25 +530,35 @@ func (p *{{ $.TypeName }}) {{ $.MethodName }}(
 	}
 
 	data := struct {
-		TypeName           string
-		UnderlyingType     string
-		UnderlyingTypeName string
-		MethodName         string
-		MethodIsVariadic   bool
-		MethodIndex        int
-		ArgNames           []string
-		ArgTypes           []string
-		ResultTypes        []string
+		TypeName            string
+		UnderlyingType      string
+		UnderlyingTypeName  string
+		UnderlyingTypeRepr  string
+		MethodName          string
+		MethodIsVariadic    bool
+		MethodIndex         int
+		ArgNames            []string
+		OriginalArgNames    []string
+		ArgTypes            []string
+		ResultTypes         []string
+		ContextArgIndex     int
+		ErrorResultIndex    int
+		SensitiveArgIndexes []int
 	}{
-		TypeName:           pg.OutputTypeName,
-		UnderlyingType:     pg.formatInputType(),
-		UnderlyingTypeName: pg.inputTypeName(),
-		MethodName:         method.Name,
-		MethodIsVariadic:   method.IsVariadic,
-		MethodIndex:        methodIndex,
-		ArgNames:           argNames,
-		ArgTypes:           argTypes,
-		ResultTypes:        resultTypes,
+		TypeName:            pg.OutputTypeName,
+		UnderlyingType:      pg.formatInputType(),
+		UnderlyingTypeName:  pg.inputTypeName(),
+		UnderlyingTypeRepr:  pg.inputPackagePath() + "." + pg.inputTypeName(),
+		MethodName:          method.Name,
+		MethodIsVariadic:    method.IsVariadic,
+		MethodIndex:         methodIndex,
+		ArgNames:            argNames,
+		OriginalArgNames:    originalArgNames,
+		ArgTypes:            argTypes,
+		ResultTypes:         resultTypes,
+		ContextArgIndex:     method.ContextArgIndex(),
+		ErrorResultIndex:    method.ErrorResultIndex(),
+		SensitiveArgIndexes: method.SensitiveArgIndexes(pg.RedactArgNamePatterns),
 	}
 
 	if err := template.Must(template.New("").Funcs(funcMap).Parse(text)).Execute(&pg.buffer, data); err != nil {
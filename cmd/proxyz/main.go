@@ -15,12 +15,13 @@ import (
 
 func main() {
 	var args struct {
-		InputPackagePattern  string `arg:"positional,required,--ipkg" help:"input package"`
-		InputTypeName        string `arg:"positional,required,--itype" help:"input type"`
-		OutputPackagePattern string `arg:"positional,required,--opkg" help:"output package"`
-		OutputTypeName       string `arg:"positional,required,--otype" help:"output type"`
-		FormatOutput         bool   `arg:"-f,--format" default:"true" help:"format output"`
-		OutputFileName       string `arg:"-w,--write" help:"write output to file inside output package directory" placeholder:"FILE"`
+		InputPackagePattern  string   `arg:"positional,required,--ipkg" help:"input package"`
+		InputTypeName        string   `arg:"positional,required,--itype" help:"input type"`
+		OutputPackagePattern string   `arg:"positional,required,--opkg" help:"output package"`
+		OutputTypeName       string   `arg:"positional,required,--otype" help:"output type"`
+		FormatOutput         bool     `arg:"-f,--format" default:"true" help:"format output"`
+		OutputFileName       string   `arg:"-w,--write" help:"write output to file inside output package directory" placeholder:"FILE"`
+		RedactArgPatterns    []string `arg:"--redact,separate" help:"mark args whose names match glob pattern as sensitive" placeholder:"PATTERN"`
 	}
 
 	arg.MustParse(&args)
@@ -43,9 +44,10 @@ func main() {
 	}
 
 	proxyGen := proxygen.ProxyGen{
-		MethodSet:            &methodSet,
-		OutputPackagePattern: args.OutputPackagePattern,
-		OutputTypeName:       args.OutputTypeName,
+		MethodSet:             &methodSet,
+		OutputPackagePattern:  args.OutputPackagePattern,
+		OutputTypeName:        args.OutputTypeName,
+		RedactArgNamePatterns: args.RedactArgPatterns,
 	}
 
 	output, err := proxyGen.EmitProgram()
@@ -0,0 +1,123 @@
+package proxyz
+
+import (
+	"math/rand"
+	"sync/atomic"
+)
+
+// MethodCallPredicate is the type of function deciding whether to act on
+// a method call.
+type MethodCallPredicate func(methodCall MethodCall) bool
+
+// Chain returns an interceptor calling the given interceptors in order, as
+// if they were added one by one.
+func Chain(methodCallInterceptors ...MethodCallInterceptor) MethodCallInterceptor {
+	switch len(methodCallInterceptors) {
+	case 0:
+		return forwardMethodCall
+	case 1:
+		return methodCallInterceptors[0]
+	}
+
+	methodCallInterceptors = append([]MethodCallInterceptor(nil), methodCallInterceptors...)
+
+	return func(methodCall MethodCall) {
+		chainedMethodCall := chainedMethodCall{
+			MethodCall:   methodCall,
+			interceptors: methodCallInterceptors,
+		}
+
+		chainedMethodCall.Forward()
+	}
+}
+
+// When returns an interceptor calling the given interceptor only for the
+// method calls satisfying the given predicate, and forwarding the others.
+func When(predicate MethodCallPredicate, methodCallInterceptor MethodCallInterceptor) MethodCallInterceptor {
+	return func(methodCall MethodCall) {
+		if !predicate(methodCall) {
+			methodCall.Forward()
+			return
+		}
+
+		methodCallInterceptor(methodCall)
+	}
+}
+
+// OnlyMethods returns an interceptor calling the given interceptor only for
+// the calls to the methods with the given names, and forwarding the others.
+func OnlyMethods(methodCallInterceptor MethodCallInterceptor, methodNames ...string) MethodCallInterceptor {
+	methodNameSet := makeMethodNameSet(methodNames)
+
+	return When(func(methodCall MethodCall) bool {
+		_, ok := methodNameSet[methodCall.MethodName()]
+		return ok
+	}, methodCallInterceptor)
+}
+
+// ExceptMethods returns an interceptor calling the given interceptor except
+// for the calls to the methods with the given names, which are forwarded.
+func ExceptMethods(methodCallInterceptor MethodCallInterceptor, methodNames ...string) MethodCallInterceptor {
+	methodNameSet := makeMethodNameSet(methodNames)
+
+	return When(func(methodCall MethodCall) bool {
+		_, ok := methodNameSet[methodCall.MethodName()]
+		return !ok
+	}, methodCallInterceptor)
+}
+
+// Sampled returns an interceptor calling the given interceptor for a random
+// fraction, in the range [0, 1], of the method calls, and forwarding the
+// others.
+func Sampled(rate float64, methodCallInterceptor MethodCallInterceptor) MethodCallInterceptor {
+	return When(func(MethodCall) bool {
+		return rand.Float64() < rate
+	}, methodCallInterceptor)
+}
+
+// Once returns an interceptor calling the given interceptor only for the
+// first method call, and forwarding the others.
+func Once(methodCallInterceptor MethodCallInterceptor) MethodCallInterceptor {
+	var done uint32
+
+	return When(func(MethodCall) bool {
+		return atomic.CompareAndSwapUint32(&done, 0, 1)
+	}, methodCallInterceptor)
+}
+
+type chainedMethodCall struct {
+	MethodCall
+
+	interceptors         []MethodCallInterceptor
+	nextInterceptorIndex int
+}
+
+func (cmc *chainedMethodCall) Forward() {
+	if i := cmc.nextInterceptorIndex; i < len(cmc.interceptors) {
+		cmc.nextInterceptorIndex++
+		cmc.interceptors[i](cmc)
+		return
+	}
+
+	cmc.MethodCall.Forward()
+}
+
+func (cmc *chainedMethodCall) Clone() MethodCall {
+	clone := *cmc
+	clone.MethodCall = cmc.MethodCall.Clone()
+	return &clone
+}
+
+func forwardMethodCall(methodCall MethodCall) {
+	methodCall.Forward()
+}
+
+func makeMethodNameSet(methodNames []string) map[string]struct{} {
+	methodNameSet := make(map[string]struct{}, len(methodNames))
+
+	for _, methodName := range methodNames {
+		methodNameSet[methodName] = struct{}{}
+	}
+
+	return methodNameSet
+}
@@ -1,6 +1,15 @@
 // Package proxyz defines common interfaces and utility for generated code.
 package proxyz
 
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
 // MethodCallInterceptor is the type of function intercepting calls to methods.
 type MethodCallInterceptor func(methodCall MethodCall)
 
@@ -16,15 +25,21 @@ type MethodCall interface {
 	// GetArg returns the argument of the method call at the given index.
 	GetArg(argIndex int) (arg interface{})
 
-	// SetArg sets the argument of the method call at the given index.
+	// SetArg sets the argument of the method call at the given index. A nil
+	// argument sets the zero value.
 	SetArg(argIndex int, arg interface{})
 
 	// GetResult returns the result of the method call at the given index.
 	GetResult(resultIndex int) (result interface{})
 
-	// SetResult sets the result of the method call at the given index.
+	// SetResult sets the result of the method call at the given index. A nil
+	// result sets the zero value.
 	SetResult(resultIndex int, result interface{})
 
+	// UnderlyingType returns the representation of the underlying type of the
+	// proxy through which the method is called (see Proxy.XxxUnderlyingType).
+	UnderlyingType() string
+
 	// MethodName returns the name of the method called.
 	MethodName() string
 
@@ -34,8 +49,318 @@ type MethodCall interface {
 	// NumberOfArgs returns the number of the arguments of the method call.
 	NumberOfArgs() int
 
+	// ArgName returns the name of the argument of the method call at the
+	// given index, or "" if the argument is unnamed.
+	ArgName(argIndex int) string
+
 	// NumberOfResults returns the number of the results of the method call.
 	NumberOfResults() int
+
+	// ContextArgIndex returns the index of the first argument of the method
+	// call which is of type context.Context, or -1 if there is none.
+	ContextArgIndex() int
+
+	// ErrorResultIndex returns the index of the last result of the method
+	// call if it is of type error, or -1 otherwise.
+	ErrorResultIndex() int
+
+	// IsArgSensitive indicates whether the argument of the method call at
+	// the given index is marked as sensitive, which should not be exposed.
+	IsArgSensitive(argIndex int) bool
+
+	// String returns the representation of the method call, with the
+	// sensitive arguments redacted.
+	String() string
+
+	// CallID returns the unique id of the method call.
+	CallID() uint64
+
+	// StartTime returns the time when the method call started.
+	StartTime() time.Time
+
+	// ParentCall returns the method call in which the method call is nested,
+	// if known through the context.Context argument, or nil otherwise. The
+	// parent is only known if it was intercepted, as the calls to the methods
+	// without any interceptors applied bypass the method call machinery and
+	// don't attach themselves to their contexts.
+	ParentCall() MethodCall
+
+	// Values returns the key/value bag shared along the interceptor chain
+	// of the method call.
+	Values() map[interface{}]interface{}
+
+	// Callee returns the underlying object to which the method call will be
+	// finally forwarded.
+	Callee() interface{}
+
+	// SetCallee sets the underlying object to which the method call will be
+	// finally forwarded, which should be of the underlying type of the proxy.
+	SetCallee(callee interface{})
+
+	// Clone returns an independent copy of the method call, with the
+	// arguments, results and values copied, and a new call id and start
+	// time, which continues from the same position of the interceptor chain
+	// when forwarded, e.g. for issuing concurrent attempts of the method
+	// call. The context.Context argument of the copy, if any, carries the
+	// copy instead (see MethodCallFromContext).
+	Clone() MethodCall
+}
+
+// XxxMethodCallBase represents the base of method calls generated.
+type XxxMethodCallBase struct {
+	callID     uint64
+	startTime  time.Time
+	parentCall MethodCall
+	values     map[interface{}]interface{}
+}
+
+// XxxInit initializes the base of the given method call. If the method call
+// has a context.Context argument, the argument will be replaced with a copy
+// carrying the method call, so that the method calls nested can find their
+// parent. That costs an allocation per intercepted call and makes the method
+// call escape to the heap through the context passed to the underlying
+// object. It serves for generated code.
+func (mcb *XxxMethodCallBase) XxxInit(methodCall MethodCall) {
+	mcb.callID = atomic.AddUint64(&lastCallID, 1)
+	mcb.startTime = time.Now()
+
+	if ctx, ok := GetContext(methodCall); ok {
+		mcb.parentCall = MethodCallFromContext(ctx)
+		methodCall.SetArg(methodCall.ContextArgIndex(), ContextWithMethodCall(ctx, methodCall))
+	}
+}
+
+// CallID implements MethodCall.CallID.
+func (mcb *XxxMethodCallBase) CallID() uint64 { return mcb.callID }
+
+// StartTime implements MethodCall.StartTime.
+func (mcb *XxxMethodCallBase) StartTime() time.Time { return mcb.startTime }
+
+// ParentCall implements MethodCall.ParentCall.
+func (mcb *XxxMethodCallBase) ParentCall() MethodCall { return mcb.parentCall }
+
+// Values implements MethodCall.Values.
+func (mcb *XxxMethodCallBase) Values() map[interface{}]interface{} {
+	if mcb.values == nil {
+		mcb.values = make(map[interface{}]interface{})
+	}
+
+	return mcb.values
+}
+
+// XxxInitClone initializes the base of the given clone of a method call,
+// which is a shallow copy of the method call, with a new call id, a new start
+// time and a copy of the values. If the clone has a context.Context argument,
+// the argument will be replaced with a copy carrying the clone. It serves for
+// generated code.
+func (mcb *XxxMethodCallBase) XxxInitClone(clone MethodCall) {
+	mcb.callID = atomic.AddUint64(&lastCallID, 1)
+	mcb.startTime = time.Now()
+
+	if values := mcb.values; values != nil {
+		mcb.values = make(map[interface{}]interface{}, len(values))
+
+		for key, value := range values {
+			mcb.values[key] = value
+		}
+	}
+
+	if ctx, ok := GetContext(clone); ok {
+		clone.SetArg(clone.ContextArgIndex(), ContextWithMethodCall(ctx, clone))
+	}
+}
+
+var lastCallID uint64
+
+// ContextWithMethodCall returns a copy of the given context carrying the given
+// method call.
+func ContextWithMethodCall(ctx context.Context, methodCall MethodCall) context.Context {
+	return context.WithValue(ctx, methodCallKey{}, methodCall)
+}
+
+// MethodCallFromContext returns the method call carried by the given context,
+// or nil if there is none. The contexts passed to the underlying objects carry
+// the method calls only if the method calls are intercepted, i.e. the calls to
+// the methods without any interceptors applied are invisible.
+func MethodCallFromContext(ctx context.Context) MethodCall {
+	methodCall, _ := ctx.Value(methodCallKey{}).(MethodCall)
+	return methodCall
+}
+
+type methodCallKey struct{}
+
+// WithInterceptor returns a copy of the given context carrying the given
+// interceptor, which intercepts the calls to the methods of all the proxies
+// having the context, or one derived from it, as the context.Context
+// argument, after all the other interceptors. Interceptors carried by the
+// same context are called in the order they are attached.
+func WithInterceptor(ctx context.Context, methodCallInterceptor MethodCallInterceptor) context.Context {
+	if atomic.LoadInt32(&contextInterceptorsUsed) == 0 {
+		atomic.StoreInt32(&contextInterceptorsUsed, 1)
+	}
+
+	oldItems := InterceptorsFromContext(ctx)
+	newItems := make([]MethodCallInterceptor, len(oldItems), len(oldItems)+1)
+	copy(newItems, oldItems)
+	return context.WithValue(ctx, interceptorsKey{}, append(newItems, methodCallInterceptor))
+}
+
+// InterceptorsFromContext returns the interceptors carried by the given
+// context (see WithInterceptor).
+func InterceptorsFromContext(ctx context.Context) []MethodCallInterceptor {
+	items, _ := ctx.Value(interceptorsKey{}).([]MethodCallInterceptor)
+	return items
+}
+
+type interceptorsKey struct{}
+
+// contextInterceptorsUsed is set once WithInterceptor is called, so that the
+// calls never look up interceptors in their contexts until then.
+var contextInterceptorsUsed int32
+
+// GetContext returns the context.Context argument of the given method call.
+// It returns false if the method has no such argument or the argument is nil.
+func GetContext(methodCall MethodCall) (context.Context, bool) {
+	argIndex := methodCall.ContextArgIndex()
+
+	if argIndex < 0 {
+		return nil, false
+	}
+
+	ctx, _ := methodCall.GetArg(argIndex).(context.Context)
+	return ctx, ctx != nil
+}
+
+// GetError returns the trailing error result of the given method call.
+// It returns nil if the method has no such result.
+func GetError(methodCall MethodCall) error {
+	resultIndex := methodCall.ErrorResultIndex()
+
+	if resultIndex < 0 {
+		return nil
+	}
+
+	err, _ := methodCall.GetResult(resultIndex).(error)
+	return err
+}
+
+// SetError sets the trailing error result of the given method call to the
+// given non-nil error. It returns false if the method has no such result.
+func SetError(methodCall MethodCall, err error) bool {
+	resultIndex := methodCall.ErrorResultIndex()
+
+	if resultIndex < 0 {
+		return false
+	}
+
+	methodCall.SetResult(resultIndex, err)
+	return true
+}
+
+// Outcome represents how forwarding a method call ended.
+type Outcome int
+
+const (
+	// Returned means the method call returned normally.
+	Returned Outcome = iota
+
+	// Panicked means the method call panicked.
+	Panicked
+
+	// Goexited means the goroutine exited through runtime.Goexit, e.g. by
+	// testing.T.FailNow, during the method call.
+	Goexited
+)
+
+// String returns the representation of the outcome.
+func (o Outcome) String() string {
+	switch o {
+	case Returned:
+		return "returned"
+	case Panicked:
+		return "panicked"
+	case Goexited:
+		return "goexited"
+	default:
+		return fmt.Sprintf("Outcome(%d)", int(o))
+	}
+}
+
+// ForwardAndObserve forwards the given method call and calls the given
+// observer with the outcome, and the panic value if panicked, before the
+// panic or the runtime.Goexit, if any, resumes unwinding the stack. Since
+// panic(nil) can not be told from runtime.Goexit before Go 1.21, it is
+// observed as Goexited and NOT resumed.
+func ForwardAndObserve(methodCall MethodCall, observer func(outcome Outcome, panicValue interface{})) {
+	returned := false
+
+	defer func() {
+		if returned {
+			observer(Returned, nil)
+			return
+		}
+
+		panicValue := recover()
+
+		if panicValue == nil {
+			observer(Goexited, nil)
+			return
+		}
+
+		observer(Panicked, panicValue)
+		panic(panicValue)
+	}()
+
+	methodCall.Forward()
+	returned = true
+}
+
+// Redacted is the placeholder of the sensitive arguments.
+const Redacted = "[REDACTED]"
+
+// FormatArg returns the representation of the argument of the given method
+// call at the given index, which is Redacted if the argument is sensitive,
+// or "<context>" if the argument is of type context.Context.
+func FormatArg(methodCall MethodCall, argIndex int) string {
+	if methodCall.IsArgSensitive(argIndex) {
+		return Redacted
+	}
+
+	if argIndex == methodCall.ContextArgIndex() {
+		return "<context>"
+	}
+
+	return fmt.Sprintf("%v", methodCall.GetArg(argIndex))
+}
+
+// FormatArgs returns the representations of all the arguments of the given
+// method call, formatted by FormatArg.
+func FormatArgs(methodCall MethodCall) []string {
+	args := make([]string, methodCall.NumberOfArgs())
+
+	for i := range args {
+		args[i] = FormatArg(methodCall, i)
+	}
+
+	return args
+}
+
+// FormatResults returns the representations of all the results of the given
+// method call, formatted by fmt.Sprintf with "%v".
+func FormatResults(methodCall MethodCall) []string {
+	results := make([]string, methodCall.NumberOfResults())
+
+	for i := range results {
+		results[i] = fmt.Sprintf("%v", methodCall.GetResult(i))
+	}
+
+	return results
+}
+
+// FormatMethodCall returns the representation of the given method call, e.g.
+// `Login(<context>, foo, [REDACTED])`. It serves for generated code.
+func FormatMethodCall(methodCall MethodCall) string {
+	return methodCall.MethodName() + "(" + strings.Join(FormatArgs(methodCall), ", ") + ")"
 }
 
 // Proxy represents a proxy generated.
@@ -44,6 +369,37 @@ type Proxy interface {
 	// to the method at the given index.
 	XxxInterceptMethodCall(methodIndex int, methodCallInterceptor MethodCallInterceptor)
 
+	// XxxAddMethodCallInterceptor adds an interceptor with the given options
+	// to intercept the calls to the method at the given index.
+	XxxAddMethodCallInterceptor(methodIndex int, methodCallInterceptor MethodCallInterceptor, options InterceptorOptions)
+
+	// XxxReplaceMethodCallInterceptor replaces the interceptor with the given
+	// name, applied to the calls to the method at the given index, keeping
+	// its options. It returns false if no such interceptor is found.
+	XxxReplaceMethodCallInterceptor(methodIndex int, name string, methodCallInterceptor MethodCallInterceptor) bool
+
+	// XxxRemoveMethodCallInterceptor removes the interceptor with the given
+	// name, applied to the calls to the method at the given index. It returns
+	// false if no such interceptor is found.
+	XxxRemoveMethodCallInterceptor(methodIndex int, name string) bool
+
+	// XxxListMethodCallInterceptors returns the names of the interceptors
+	// applied to the calls to the method at the given index, in the order
+	// they are called. Unnamed interceptors have empty names.
+	XxxListMethodCallInterceptors(methodIndex int) []string
+
+	// XxxDescribeMethodCallInterceptors returns the information of the
+	// interceptors applied to the calls to the method at the given index, in
+	// the order they are called, excluding the global interceptors (see
+	// DescribeMethodCallInterceptors).
+	XxxDescribeMethodCallInterceptors(methodIndex int) []InterceptorInfo
+
+	// XxxSetEventBus attaches the given event bus to the proxy to publish the
+	// events of the calls to all the methods of the proxy. The events are
+	// published by the outermost interceptor, preceding the global ones.
+	// A nil event bus detaches the current one.
+	XxxSetEventBus(eventBus *EventBus)
+
 	// XxxGetMethodName returns the name of the method at the given index.
 	XxxGetMethodName(methodIndex int) string
 
@@ -54,40 +410,162 @@ type Proxy interface {
 	// XxxUnderlyingType returns the representation of the underlying type of
 	// the proxy generated.
 	XxxUnderlyingType() string
+
+	// XxxIsOfUnderlyingType returns whether the given value is of the
+	// underlying type of the proxy generated, i.e. can be set as the callee of
+	// the method calls (see MethodCall.SetCallee).
+	XxxIsOfUnderlyingType(value interface{}) bool
+}
+
+// InterceptAllMethodCalls adds an interceptor to intercept the calls to all
+// the methods of the given proxy.
+func InterceptAllMethodCalls(proxy Proxy, methodCallInterceptor MethodCallInterceptor) {
+	for methodIndex, n := 0, proxy.XxxNumberOfMethods(); methodIndex < n; methodIndex++ {
+		proxy.XxxInterceptMethodCall(methodIndex, methodCallInterceptor)
+	}
 }
 
 // XxxProxyBase represents the base of proxies generated.
+//
+// The interceptors of proxies can be added, replaced and removed while the
+// methods are being called, from any goroutine.
 type XxxProxyBase struct {
+	mutex                  sync.Mutex
 	methodCallInterceptors methodCallInterceptors
+	localInterceptors      atomic.Value
+	resolvedInterceptors   resolvedInterceptors
+	eventBus               atomic.Value
 }
 
 // XxxInterceptMethodCall implements Proxy.XxxInterceptMethodCall.
 func (pb *XxxProxyBase) XxxInterceptMethodCall(methodIndex int, methodCallInterceptor MethodCallInterceptor) {
-	pb.methodCallInterceptors.AddItem(methodIndex, methodCallInterceptor)
+	pb.XxxAddMethodCallInterceptor(methodIndex, methodCallInterceptor, InterceptorOptions{})
+}
+
+// XxxAddMethodCallInterceptor implements Proxy.XxxAddMethodCallInterceptor.
+func (pb *XxxProxyBase) XxxAddMethodCallInterceptor(methodIndex int, methodCallInterceptor MethodCallInterceptor, options InterceptorOptions) {
+	pb.mutex.Lock()
+	defer pb.mutex.Unlock()
+	defer pb.storeLocalInterceptors()
+	pb.methodCallInterceptors.AddItem(methodIndex, methodCallInterceptor, options)
+}
+
+// XxxReplaceMethodCallInterceptor implements Proxy.XxxReplaceMethodCallInterceptor.
+func (pb *XxxProxyBase) XxxReplaceMethodCallInterceptor(methodIndex int, name string, methodCallInterceptor MethodCallInterceptor) bool {
+	pb.mutex.Lock()
+	defer pb.mutex.Unlock()
+
+	if !pb.methodCallInterceptors.ReplaceItem(methodIndex, name, methodCallInterceptor) {
+		return false
+	}
+
+	pb.storeLocalInterceptors()
+	return true
+}
+
+// XxxRemoveMethodCallInterceptor implements Proxy.XxxRemoveMethodCallInterceptor.
+func (pb *XxxProxyBase) XxxRemoveMethodCallInterceptor(methodIndex int, name string) bool {
+	pb.mutex.Lock()
+	defer pb.mutex.Unlock()
+
+	if !pb.methodCallInterceptors.RemoveItem(methodIndex, name) {
+		return false
+	}
+
+	pb.storeLocalInterceptors()
+	return true
 }
 
-// XxxGetMethodCallInterceptors returns the interceptors applied to the calls
-// to the method at the given index. It serves for generated code.
+// XxxListMethodCallInterceptors implements Proxy.XxxListMethodCallInterceptors.
+func (pb *XxxProxyBase) XxxListMethodCallInterceptors(methodIndex int) []string {
+	pb.mutex.Lock()
+	defer pb.mutex.Unlock()
+	return pb.methodCallInterceptors.GetItemNames(methodIndex)
+}
+
+// XxxSetEventBus implements Proxy.XxxSetEventBus.
+func (pb *XxxProxyBase) XxxSetEventBus(eventBus *EventBus) {
+	pb.eventBus.Store(eventBus)
+}
+
+// XxxGetMethodCallInterceptors returns the interceptors added to the proxy
+// to intercept the calls to the method at the given index, excluding the
+// global interceptors.
 func (pb *XxxProxyBase) XxxGetMethodCallInterceptors(methodIndex int) []MethodCallInterceptor {
-	return pb.methodCallInterceptors.GetItems(methodIndex)
+	return pb.loadLocalInterceptors().GetItems(methodIndex)
 }
 
-type methodCallInterceptors struct {
-	methodIndex2Items map[int][]MethodCallInterceptor
+// XxxResolveMethodCallInterceptors returns all the interceptors applied to
+// the calls to the method at the given index, including the global
+// interceptors and the event publishing one, of the given proxy, which should
+// be based on the proxy base. It serves for generated code.
+func (pb *XxxProxyBase) XxxResolveMethodCallInterceptors(proxy Proxy, methodIndex int) []MethodCallInterceptor {
+	globalInterceptors := loadGlobalInterceptors()
+	eventBus, _ := pb.eventBus.Load().(*EventBus)
+	localInterceptors := pb.loadLocalInterceptors()
+
+	if globalInterceptors == nil && eventBus == nil {
+		return localInterceptors.GetItems(methodIndex)
+	}
+
+	return pb.resolvedInterceptors.GetItems(proxy, methodIndex, globalInterceptors, localInterceptors, eventBus)
 }
 
-func (mci *methodCallInterceptors) AddItem(methodIndex int, item MethodCallInterceptor) {
-	if mci.methodIndex2Items == nil {
-		mci.methodIndex2Items = map[int][]MethodCallInterceptor{
-			methodIndex: {item},
-		}
-	} else {
-		items := mci.methodIndex2Items[methodIndex]
-		mci.methodIndex2Items[methodIndex] = append(items, item)
+// XxxResolveContextMethodCallInterceptors is like
+// XxxResolveMethodCallInterceptors, but also includes the interceptors carried
+// by the given context.Context argument, which can be nil. It serves for
+// generated code.
+func (pb *XxxProxyBase) XxxResolveContextMethodCallInterceptors(ctx context.Context, proxy Proxy, methodIndex int) []MethodCallInterceptor {
+	items := pb.XxxResolveMethodCallInterceptors(proxy, methodIndex)
+
+	if ctx == nil || atomic.LoadInt32(&contextInterceptorsUsed) == 0 {
+		return items
 	}
 
+	contextItems := InterceptorsFromContext(ctx)
+
+	if len(contextItems) == 0 {
+		return items
+	}
+
+	return append(items[:len(items):len(items)], contextItems...)
+}
+
+func (pb *XxxProxyBase) storeLocalInterceptors() {
+	li := localInterceptors{
+		Version:           pb.methodCallInterceptors.Version(),
+		MethodIndex2Items: make(map[int][]MethodCallInterceptor),
+	}
+
+	for methodIndex := range pb.methodCallInterceptors.methodIndex2Entries {
+		li.MethodIndex2Items[methodIndex] = pb.methodCallInterceptors.GetItems(methodIndex)
+	}
+
+	pb.localInterceptors.Store(&li)
+}
+
+func (pb *XxxProxyBase) loadLocalInterceptors() *localInterceptors {
+	li, _ := pb.localInterceptors.Load().(*localInterceptors)
+	return li
+}
+
+type localInterceptors struct {
+	Version           int
+	MethodIndex2Items map[int][]MethodCallInterceptor
 }
 
-func (mci *methodCallInterceptors) GetItems(methodIndex int) []MethodCallInterceptor {
-	return mci.methodIndex2Items[methodIndex]
+func (li *localInterceptors) GetVersion() int {
+	if li == nil {
+		return 0
+	}
+
+	return li.Version
+}
+
+func (li *localInterceptors) GetItems(methodIndex int) []MethodCallInterceptor {
+	if li == nil {
+		return nil
+	}
+
+	return li.MethodIndex2Items[methodIndex]
 }
@@ -1,11 +1,20 @@
 package proxyz_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/roy2220/proxyz"
+	"github.com/roy2220/proxyz/internal/testproxy"
 )
 
 func TestProxyBase(t *testing.T) {
@@ -28,3 +37,525 @@ func TestProxyBase(t *testing.T) {
 	}
 	assert.Equal(t, "abc", s)
 }
+
+func TestContextAndError(t *testing.T) {
+	kv := testproxy.NewKVProxy(testproxy.MapKV{})
+	ctx := context.WithValue(context.Background(), struct{}{}, "value")
+	var n int
+	kv.XxxInterceptMethodCall(testproxy.KVProxyGet, func(mc proxyz.MethodCall) {
+		ctx2, ok := proxyz.GetContext(mc)
+		assert.True(t, ok)
+		assert.Equal(t, "value", ctx2.Value(struct{}{}))
+		mc.Forward()
+		assert.Equal(t, testproxy.ErrNotFound, proxyz.GetError(mc))
+		assert.True(t, proxyz.SetError(mc, errors.New("test")))
+		n++
+	})
+	kv.XxxInterceptMethodCall(testproxy.KVProxyKeys, func(mc proxyz.MethodCall) {
+		_, ok := proxyz.GetContext(mc)
+		assert.False(t, ok)
+		mc.Forward()
+		assert.Nil(t, proxyz.GetError(mc))
+		assert.False(t, proxyz.SetError(mc, errors.New("test")))
+		n++
+	})
+	_, err := kv.Get(ctx, "foo")
+	assert.EqualError(t, err, "test")
+	assert.Empty(t, kv.Keys())
+	assert.Equal(t, 2, n)
+}
+
+func TestProxyBaseInterceptorOrder(t *testing.T) {
+	var pb proxyz.XxxProxyBase
+	var s string
+	add := func(name string, options proxyz.InterceptorOptions) {
+		options.Name = name
+		pb.XxxAddMethodCallInterceptor(100, func(_ proxyz.MethodCall) { s += name }, options)
+	}
+	call := func() string {
+		s = ""
+		for _, mci := range pb.XxxGetMethodCallInterceptors(100) {
+			mci(nil)
+		}
+		return s
+	}
+	add("a", proxyz.InterceptorOptions{})
+	add("b", proxyz.InterceptorOptions{Priority: 1})
+	add("c", proxyz.InterceptorOptions{})
+	add("d", proxyz.InterceptorOptions{Priority: -1, Before: []string{"a"}})
+	add("e", proxyz.InterceptorOptions{After: []string{"c"}, Priority: 2})
+	assert.Equal(t, "bceda", call())
+	assert.Equal(t, []string{"b", "c", "e", "d", "a"}, pb.XxxListMethodCallInterceptors(100))
+
+	assert.True(t, pb.XxxReplaceMethodCallInterceptor(100, "a", func(_ proxyz.MethodCall) { s += "A" }))
+	assert.False(t, pb.XxxReplaceMethodCallInterceptor(100, "x", func(_ proxyz.MethodCall) {}))
+	assert.Equal(t, "bcedA", call())
+
+	assert.True(t, pb.XxxRemoveMethodCallInterceptor(100, "d"))
+	assert.False(t, pb.XxxRemoveMethodCallInterceptor(100, "d"))
+	assert.False(t, pb.XxxRemoveMethodCallInterceptor(101, "a"))
+	assert.Equal(t, "bAce", call())
+
+	assert.Panics(t, func() { add("a", proxyz.InterceptorOptions{}) })
+	assert.Panics(t, func() { add("f", proxyz.InterceptorOptions{Before: []string{"c"}, After: []string{"e"}}) })
+	assert.Equal(t, []string{"b", "a", "c", "e"}, pb.XxxListMethodCallInterceptors(100))
+	assert.Nil(t, pb.XxxListMethodCallInterceptors(101))
+}
+
+func TestGlobalInterceptors(t *testing.T) {
+	kv := testproxy.NewKVProxy(testproxy.MapKV{})
+	var s string
+	kv.XxxInterceptMethodCall(testproxy.KVProxyKeys, func(mc proxyz.MethodCall) {
+		s += "c"
+		mc.Forward()
+	})
+	proxyz.AddGlobalInterceptor(nil, func(mc proxyz.MethodCall) {
+		s += "b"
+		mc.Forward()
+	}, proxyz.InterceptorOptions{Name: "b"})
+	defer proxyz.RemoveGlobalInterceptor("b")
+	proxyz.AddGlobalInterceptor(func(underlyingType string, methodName string) bool {
+		return methodName == "Keys"
+	}, func(mc proxyz.MethodCall) {
+		s += "a"
+		mc.Forward()
+	}, proxyz.InterceptorOptions{Name: "a", Priority: 1})
+	defer proxyz.RemoveGlobalInterceptor("a")
+	assert.Equal(t, []string{"a", "b"}, proxyz.ListGlobalInterceptors())
+
+	kv.Keys()
+	assert.Equal(t, "abc", s)
+	s = ""
+	kv.Set(context.Background(), "foo", "bar")
+	assert.Equal(t, "b", s)
+
+	s = ""
+	greeter := testproxy.NewGreeterProxy(testproxy.KVGreeter{KV: kv})
+	proxyz.AddGlobalInterceptor(func(underlyingType string, _ string) bool {
+		return underlyingType == greeter.XxxUnderlyingType()
+	}, func(mc proxyz.MethodCall) {
+		s += "d"
+		mc.Forward()
+	}, proxyz.InterceptorOptions{Name: "d"})
+	greeter.Greet(context.Background(), "roy")
+	assert.Equal(t, "bdb", s)
+
+	s = ""
+	assert.True(t, proxyz.RemoveGlobalInterceptor("d"))
+	assert.False(t, proxyz.RemoveGlobalInterceptor("d"))
+	assert.True(t, proxyz.RemoveGlobalInterceptor("b"))
+	greeter.Greet(context.Background(), "roy")
+	kv.Keys()
+	assert.Equal(t, "ac", s)
+}
+
+func TestMethodCallMetadata(t *testing.T) {
+	kv := testproxy.NewKVProxy(testproxy.MapKV{"greeting": "hello"})
+	greeter := testproxy.NewGreeterProxy(testproxy.KVGreeter{KV: kv})
+	var greetCall, getCall proxyz.MethodCall
+	greeter.XxxInterceptMethodCall(testproxy.GreeterProxyGreet, func(mc proxyz.MethodCall) {
+		mc.Values()["foo"] = "bar"
+		mc.Forward()
+	})
+	greeter.XxxInterceptMethodCall(testproxy.GreeterProxyGreet, func(mc proxyz.MethodCall) {
+		assert.Equal(t, "bar", mc.Values()["foo"])
+		greetCall = mc
+		mc.Forward()
+	})
+	kv.XxxInterceptMethodCall(testproxy.KVProxyGet, func(mc proxyz.MethodCall) {
+		assert.Empty(t, mc.Values())
+		getCall = mc
+		mc.Forward()
+	})
+	t0 := time.Now()
+	_, err := greeter.Greet(context.Background(), "roy")
+	assert.NoError(t, err)
+
+	assert.Nil(t, greetCall.ParentCall())
+	assert.Equal(t, greetCall, getCall.ParentCall())
+	assert.NotEqual(t, greetCall.CallID(), getCall.CallID())
+	assert.False(t, greetCall.StartTime().Before(t0))
+	assert.False(t, getCall.StartTime().Before(greetCall.StartTime()))
+	ctx, _ := proxyz.GetContext(getCall)
+	assert.Equal(t, getCall, proxyz.MethodCallFromContext(ctx))
+}
+
+func TestSensitiveArgs(t *testing.T) {
+	as := testproxy.NewAccountStoreProxy(testproxy.MapAccountStore{
+		"roy": {Name: "roy", Password: "secret"},
+	})
+	var loginCall proxyz.MethodCall
+	as.XxxInterceptMethodCall(testproxy.AccountStoreProxyLogin, func(mc proxyz.MethodCall) {
+		loginCall = mc
+		mc.Forward()
+	})
+	err := as.Login(context.Background(), "roy", "secret", "123456")
+	assert.NoError(t, err)
+
+	assert.False(t, loginCall.IsArgSensitive(0))
+	assert.False(t, loginCall.IsArgSensitive(1))
+	assert.True(t, loginCall.IsArgSensitive(2))
+	assert.True(t, loginCall.IsArgSensitive(3))
+	assert.Equal(t, "roy", proxyz.FormatArg(loginCall, 1))
+	assert.Equal(t, proxyz.Redacted, proxyz.FormatArg(loginCall, 2))
+	assert.Equal(t, "Login(<context>, roy, [REDACTED], [REDACTED])", loginCall.String())
+}
+
+func TestCombinators(t *testing.T) {
+	kv := testproxy.NewKVProxy(testproxy.MapKV{})
+	var trace string
+	tracer := func(s string) proxyz.MethodCallInterceptor {
+		return func(mc proxyz.MethodCall) {
+			trace += s
+			mc.Forward()
+		}
+	}
+	proxyz.InterceptAllMethodCalls(kv, proxyz.Chain(
+		tracer("a"),
+		proxyz.OnlyMethods(tracer("b"), "Set"),
+		proxyz.ExceptMethods(tracer("c"), "Set"),
+		proxyz.When(func(mc proxyz.MethodCall) bool { return mc.NumberOfArgs() == 0 }, tracer("d")),
+		proxyz.Once(tracer("e")),
+		proxyz.Sampled(0, tracer("f")),
+		proxyz.Sampled(1, tracer("g")),
+	))
+	proxyz.InterceptAllMethodCalls(kv, tracer("h"))
+
+	err := kv.Set(context.Background(), "foo", "bar")
+	assert.NoError(t, err)
+	assert.Equal(t, "abegh", trace)
+	trace = ""
+	value, err := kv.Get(context.Background(), "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", value)
+	assert.Equal(t, "acgh", trace)
+	trace = ""
+	assert.Equal(t, []string{"foo"}, kv.Keys())
+	assert.Equal(t, "acdgh", trace)
+
+	kv.XxxInterceptMethodCall(testproxy.KVProxyKeys, proxyz.Chain(func(mc proxyz.MethodCall) {
+		mc.SetResult(0, []string{"bypassed"})
+	}, tracer("i")))
+	trace = ""
+	assert.Equal(t, []string{"bypassed"}, kv.Keys())
+	assert.Equal(t, "acdgh", trace)
+}
+
+func TestEventBus(t *testing.T) {
+	eventBus := new(proxyz.EventBus).Init()
+	defer eventBus.Close()
+	events := make(chan *proxyz.CallEvent, 10)
+	eventBus.Subscribe(func(event *proxyz.CallEvent) { events <- event }, proxyz.SubscriptionOptions{})
+	kv := testproxy.NewKVProxy(testproxy.MapKV{"foo": "bar"})
+	kv.XxxSetEventBus(eventBus)
+	kv.XxxInterceptMethodCall(testproxy.KVProxyGet, func(mc proxyz.MethodCall) {
+		mc.SetArg(1, "foo")
+		mc.Forward()
+	})
+
+	value, err := kv.Get(context.Background(), "xyz")
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", value)
+	_, err = kv.Get(context.Background(), "xyz")
+	assert.NoError(t, err)
+	err = kv.Set(context.Background(), "foo", "baz")
+	assert.NoError(t, err)
+
+	event := <-events
+	assert.Equal(t, "github.com/roy2220/proxyz/internal/testproxy.KV", event.UnderlyingType)
+	assert.Equal(t, "Get", event.MethodName)
+	assert.Equal(t, []string{"<context>", "xyz"}, event.Args)
+	assert.Equal(t, []string{"bar", "<nil>"}, event.Results)
+	assert.NoError(t, event.Err)
+	assert.True(t, event.Duration >= 0)
+	<-events
+	event = <-events
+	assert.Equal(t, "Set", event.MethodName)
+
+	kv.XxxSetEventBus(nil)
+	kv.Keys()
+	time.Sleep(10 * time.Millisecond)
+	assert.Empty(t, events)
+}
+
+func TestEventBusDropPolicy(t *testing.T) {
+	for _, dropPolicy := range []proxyz.DropPolicy{proxyz.DropNewest, proxyz.DropOldest} {
+		eventBus := new(proxyz.EventBus).Init()
+		var methodNames []string
+		unblock := make(chan struct{})
+		subscription := eventBus.Subscribe(func(event *proxyz.CallEvent) {
+			<-unblock
+			methodNames = append(methodNames, event.MethodName+event.Args[1])
+		}, proxyz.SubscriptionOptions{BufferSize: 1, DropPolicy: dropPolicy})
+		kv := testproxy.NewKVProxy(testproxy.MapKV{})
+		kv.XxxSetEventBus(eventBus)
+
+		kv.Get(context.Background(), "a")
+		time.Sleep(10 * time.Millisecond) // wait for the observer to take the event
+		kv.Get(context.Background(), "b")
+		kv.Get(context.Background(), "c")
+		assert.Equal(t, uint64(1), subscription.DroppedCount())
+		close(unblock)
+		subscription.Unsubscribe()
+
+		if dropPolicy == proxyz.DropNewest {
+			assert.Equal(t, []string{"Geta", "Getb"}, methodNames)
+		} else {
+			assert.Equal(t, []string{"Geta", "Getc"}, methodNames)
+		}
+	}
+}
+
+func TestInterceptionConfig(t *testing.T) {
+	_, err := proxyz.ReadInterceptionConfig(strings.NewReader(`{"rules": [{"type": "*", "action": "explode"}]}`))
+	assert.EqualError(t, err, `proxyz: invalid interception rule; ruleIndex=0: unknown action "explode"`)
+	_, err = proxyz.ReadInterceptionConfig(strings.NewReader(`{"rules": [{"type": "*", "action": "delay"}]}`))
+	assert.Error(t, err)
+
+	config, err := proxyz.ReadInterceptionConfig(strings.NewReader(`{
+	"rules": [
+		{"type": "testproxy.AccountStore", "action": "count"},
+		{"type": "github.com/roy2220/proxyz/internal/testproxy.AccountStore", "method": "Login", "action": "log"},
+		{"type": "testproxy.*", "method": "Login", "action": "delay", "delay": "20ms"},
+		{"type": "testproxy.AccountStore", "method": "CreateAccount", "action": "fail", "error": "read only"}
+	]
+}`))
+	require.NoError(t, err)
+	var buffer bytes.Buffer
+	err = proxyz.ApplyInterceptionConfig(config, &buffer)
+	require.NoError(t, err)
+	defer func() {
+		for _, name := range proxyz.ListGlobalInterceptors() {
+			if strings.HasPrefix(name, "proxyz.config.") {
+				proxyz.RemoveGlobalInterceptor(name)
+			}
+		}
+	}()
+
+	proxyz.ResetInterceptionCounts()
+	as := testproxy.NewAccountStoreProxy(testproxy.MapAccountStore{
+		"roy": {Name: "roy", Password: "secret"},
+	})
+	t0 := time.Now()
+	err = as.Login(context.Background(), "roy", "secret", "123456")
+	assert.NoError(t, err)
+	assert.True(t, time.Since(t0) >= 20*time.Millisecond)
+	assert.Regexp(t, `^proxyz: method call; callID=\d+ method=github.com/roy2220/proxyz/internal/testproxy.AccountStore.Login `+
+		`args=\[<context>, roy, \[REDACTED\], \[REDACTED\]\] duration=\S+ results=\[<nil>\]\n$`, buffer.String())
+	err = as.CreateAccount(context.Background(), &testproxy.Account{Name: "foo"})
+	assert.EqualError(t, err, "read only")
+	assert.Equal(t, 1, as.CountAccounts())
+	counts := proxyz.InterceptionCounts()
+	assert.Equal(t, uint64(1), counts["github.com/roy2220/proxyz/internal/testproxy.AccountStore.Login"])
+	assert.Equal(t, uint64(1), counts["github.com/roy2220/proxyz/internal/testproxy.AccountStore.CreateAccount"])
+
+	buffer.Reset()
+	err = proxyz.TraceCalls("testproxy.KV", &buffer)
+	require.NoError(t, err)
+	kv := testproxy.NewKVProxy(testproxy.MapKV{})
+	kv.Keys()
+	as.CountAccounts()
+	assert.Contains(t, buffer.String(), "testproxy.KV.Keys args=[]")
+	assert.NotContains(t, buffer.String(), "CountAccounts")
+}
+
+func TestInterceptorIntrospection(t *testing.T) {
+	kv := testproxy.NewKVProxy(testproxy.MapKV{})
+	kv.XxxAddMethodCallInterceptor(testproxy.KVProxyKeys, func(mc proxyz.MethodCall) {
+		time.Sleep(10 * time.Millisecond)
+		mc.Forward()
+	}, proxyz.InterceptorOptions{Name: "sleep", Priority: 1})
+	kv.XxxInterceptMethodCall(testproxy.KVProxyKeys, func(mc proxyz.MethodCall) {
+		mc.Forward()
+	})
+	proxyz.AddGlobalInterceptor(func(_ string, methodName string) bool {
+		return methodName == "Keys"
+	}, func(mc proxyz.MethodCall) {
+		mc.Forward()
+	}, proxyz.InterceptorOptions{Name: "global"})
+	defer proxyz.RemoveGlobalInterceptor("global")
+
+	kv.Keys()
+	infos := proxyz.DescribeMethodCallInterceptors(kv, testproxy.KVProxyKeys)
+	require.Len(t, infos, 3)
+	assert.Equal(t, uint64(0), infos[1].CallCount)
+
+	proxyz.EnableInterceptorStats(true)
+	defer proxyz.EnableInterceptorStats(false)
+	kv.Keys()
+	kv.Keys()
+	infos = proxyz.DescribeMethodCallInterceptors(kv, testproxy.KVProxyKeys)
+	require.Len(t, infos, 3)
+	assert.Equal(t, "global", infos[0].Name)
+	assert.True(t, infos[0].IsGlobal)
+	assert.Equal(t, uint64(2), infos[0].CallCount)
+	assert.Equal(t, "sleep", infos[1].Name)
+	assert.Equal(t, 1, infos[1].Priority)
+	assert.False(t, infos[1].IsGlobal)
+	assert.Equal(t, uint64(2), infos[1].CallCount)
+	assert.True(t, infos[1].TotalTime >= 20*time.Millisecond)
+	assert.True(t, infos[0].TotalTime >= infos[1].TotalTime)
+	assert.Equal(t, "", infos[2].Name)
+	assert.Equal(t, uint64(2), infos[2].CallCount)
+	assert.True(t, infos[2].TotalTime < 10*time.Millisecond)
+	assert.Equal(t, infos[1:], kv.XxxDescribeMethodCallInterceptors(testproxy.KVProxyKeys))
+
+	kv.XxxReplaceMethodCallInterceptor(testproxy.KVProxyKeys, "sleep", func(mc proxyz.MethodCall) { mc.Forward() })
+	infos = kv.XxxDescribeMethodCallInterceptors(testproxy.KVProxyKeys)
+	assert.Equal(t, uint64(0), infos[0].CallCount)
+
+	kv.XxxSetEventBus(new(proxyz.EventBus).Init())
+	var buffer bytes.Buffer
+	err := proxyz.DumpInterceptors(&buffer, kv)
+	require.NoError(t, err)
+	assert.Regexp(t, `^github.com/roy2220/proxyz/internal/testproxy.KV:
+	\(event bus attached\)
+	Get:
+		\(none\)
+	Keys:
+		1\. global \[global\] priority=0 calls=2 totalTime=\S+
+		2\. sleep priority=1 calls=0 totalTime=0s
+		3\. \(unnamed\) priority=0 calls=2 totalTime=\S+
+	Set:
+		\(none\)
+$`, buffer.String())
+}
+
+func TestProxyRegistry(t *testing.T) {
+	kv1 := testproxy.NewKVProxy(testproxy.MapKV{})
+	kv2 := testproxy.NewKVProxy(testproxy.MapKV{})
+	require.NoError(t, proxyz.RegisterProxy("kv2", kv2))
+	require.NoError(t, proxyz.RegisterProxy("kv1", kv1))
+	assert.Error(t, proxyz.RegisterProxy("kv1", kv2))
+	assert.Error(t, proxyz.RegisterProxy("kv/3", kv2))
+	assert.Error(t, proxyz.RegisterProxy("", kv2))
+
+	proxy, ok := proxyz.LookupProxy("kv1")
+	assert.True(t, ok)
+	assert.Equal(t, proxyz.Proxy(kv1), proxy)
+	registeredProxies := proxyz.ListProxies()
+	require.Len(t, registeredProxies, 2)
+	assert.Equal(t, "kv1", registeredProxies[0].Name)
+	assert.Equal(t, "kv2", registeredProxies[1].Name)
+
+	assert.True(t, proxyz.UnregisterProxy("kv1"))
+	assert.False(t, proxyz.UnregisterProxy("kv1"))
+	assert.True(t, proxyz.UnregisterProxy("kv2"))
+	_, ok = proxyz.LookupProxy("kv1")
+	assert.False(t, ok)
+	assert.Empty(t, proxyz.ListProxies())
+}
+
+func TestContextInterceptors(t *testing.T) {
+	kv := testproxy.NewKVProxy(testproxy.MapKV{"foo": "bar"})
+	var s string
+	kv.XxxInterceptMethodCall(testproxy.KVProxyGet, func(mc proxyz.MethodCall) {
+		s += "a"
+		mc.Forward()
+	})
+	ctx := context.Background()
+	ctx1 := proxyz.WithInterceptor(ctx, func(mc proxyz.MethodCall) {
+		s += "b"
+		mc.Forward()
+	})
+	ctx2 := proxyz.WithInterceptor(ctx1, func(mc proxyz.MethodCall) {
+		s += "c"
+		proxyz.SetError(mc, errors.New("injected"))
+	})
+	assert.Len(t, proxyz.InterceptorsFromContext(ctx1), 1)
+	assert.Len(t, proxyz.InterceptorsFromContext(ctx2), 2)
+
+	v, err := kv.Get(ctx1, "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", v)
+	assert.Equal(t, "ab", s)
+
+	s = ""
+	_, err = kv.Get(ctx2, "foo")
+	assert.EqualError(t, err, "injected")
+	assert.Equal(t, "abc", s)
+	assert.NoError(t, kv.Set(ctx1, "foo", "baz"))
+	assert.Equal(t, "abcb", s)
+
+	s = ""
+	v, err = kv.Get(ctx, "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "baz", v)
+	assert.Equal(t, "a", s)
+	kv.Keys()
+	assert.Equal(t, "a", s)
+}
+
+func TestMethodCallClone(t *testing.T) {
+	kv := testproxy.NewKVProxy(testproxy.MapKV{"foo": "bar"})
+	var s string
+	var clone proxyz.MethodCall
+	kv.XxxInterceptMethodCall(testproxy.KVProxyGet, proxyz.Chain(func(mc proxyz.MethodCall) {
+		mc.Values()["k"] = "v1"
+		clone = mc.Clone()
+		clone2 := mc.Clone()
+		assert.NotEqual(t, mc.CallID(), clone.CallID())
+		assert.NotEqual(t, clone.CallID(), clone2.CallID())
+		assert.Equal(t, mc.ParentCall(), clone.ParentCall())
+		ctx, _ := proxyz.GetContext(clone)
+		assert.Equal(t, clone.CallID(), proxyz.MethodCallFromContext(ctx).CallID())
+		ctx, _ = proxyz.GetContext(mc)
+		assert.Equal(t, mc.CallID(), proxyz.MethodCallFromContext(ctx).CallID())
+		clone.Values()["k"] = "v2"
+		clone.SetArg(1, "baz")
+		clone.Forward()
+		assert.Equal(t, "", clone.GetResult(0))
+		assert.Equal(t, testproxy.ErrNotFound, clone.GetResult(1))
+		assert.Equal(t, "foo", mc.GetArg(1))
+		assert.Equal(t, "v1", mc.Values()["k"])
+		mc.Forward()
+		clone.SetResult(1, nil)
+		assert.Nil(t, clone.GetResult(1))
+	}, func(mc proxyz.MethodCall) {
+		s += fmt.Sprint(mc.GetArg(1))
+		mc.Forward()
+	}))
+
+	v, err := kv.Get(context.Background(), "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", v)
+	assert.Equal(t, "bazfoo", s)
+	assert.Equal(t, "v2", clone.Values()["k"])
+}
+
+func TestForwardAndObserve(t *testing.T) {
+	kv := testproxy.NewKVProxy(testproxy.MapKV{"foo": "bar"})
+	var outcomes []proxyz.Outcome
+	var panicValues []interface{}
+	kv.XxxInterceptMethodCall(testproxy.KVProxyGet, func(mc proxyz.MethodCall) {
+		proxyz.ForwardAndObserve(mc, func(outcome proxyz.Outcome, panicValue interface{}) {
+			outcomes = append(outcomes, outcome)
+			panicValues = append(panicValues, panicValue)
+		})
+	})
+	kv.XxxInterceptMethodCall(testproxy.KVProxyGet, func(mc proxyz.MethodCall) {
+		switch mc.GetArg(1) {
+		case "panic":
+			panic("boom")
+		case "goexit":
+			runtime.Goexit()
+		}
+
+		mc.Forward()
+	})
+
+	v, _ := kv.Get(context.Background(), "foo")
+	assert.Equal(t, "bar", v)
+	assert.PanicsWithValue(t, "boom", func() { kv.Get(context.Background(), "panic") })
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		kv.Get(context.Background(), "goexit")
+	}()
+
+	<-done
+	assert.Equal(t, []proxyz.Outcome{proxyz.Returned, proxyz.Panicked, proxyz.Goexited}, outcomes)
+	assert.Equal(t, []interface{}{nil, "boom", nil}, panicValues)
+	assert.Equal(t, "goexited", proxyz.Goexited.String())
+}
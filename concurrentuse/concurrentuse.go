@@ -0,0 +1,248 @@
+// Package concurrentuse provides an interceptor detecting the overlapping
+// method calls on the same underlying objects from different goroutines, for
+// the underlying types not safe for concurrent use.
+package concurrentuse
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/roy2220/proxyz"
+	"github.com/roy2220/proxyz/internal/goroutine"
+	"github.com/roy2220/proxyz/internal/reporting"
+)
+
+// Call represents a method call involved in an overlap.
+type Call struct {
+	CallID      uint64
+	MethodName  string
+	Args        []string
+	StartTime   time.Time
+	GoroutineID int64
+
+	// Stack is the stack of the goroutine making the method call, if found.
+	Stack string
+}
+
+// Report represents a report of overlapping method calls.
+type Report struct {
+	UnderlyingType string
+
+	// Call is the method call started while OverlappedCall was in flight.
+	Call Call
+
+	// OverlappedCall is the method call in flight on the same underlying
+	// object.
+	OverlappedCall Call
+}
+
+// WriteTo writes the report in text to the given writer.
+func (r *Report) WriteTo(writer io.Writer) (int64, error) {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "concurrentuse: overlapping method calls; type=%s\n", r.UnderlyingType)
+
+	for _, call := range [...]*Call{&r.Call, &r.OverlappedCall} {
+		fmt.Fprintf(&buffer, "\tcallID=%d method=%s goroutineID=%d\n", call.CallID, call.MethodName, call.GoroutineID)
+
+		reporting.WriteArgs(&buffer, "\t\t", call.Args)
+
+		if call.Stack != "" {
+			buffer.WriteString(call.Stack)
+			buffer.WriteString("\n\n")
+		}
+	}
+
+	return buffer.WriteTo(writer)
+}
+
+// Options represents options for detectors.
+type Options struct {
+	// SharedMethods are the patterns, in the syntax of path.Match, of the
+	// names of the methods safe for concurrent use with each other, usually
+	// the read-only ones. They still must not overlap with the other methods.
+	SharedMethods []string
+
+	// ExcludedMethods are the patterns, in the syntax of path.Match, of the
+	// names of the methods safe for concurrent use with any method, which
+	// are not tracked.
+	ExcludedMethods []string
+
+	// Reporter reports overlapping method calls. If not specified, the
+	// reports will be written to stderr.
+	Reporter func(report *Report)
+}
+
+// Detector detects the overlapping method calls on the same underlying
+// objects from different goroutines.
+type Detector struct {
+	options         Options
+	mutex           sync.Mutex
+	callee2InFlight map[interface{}]map[uint64]*callEntry
+}
+
+type callEntry struct {
+	Call
+	IsShared bool
+}
+
+// Init initializes the detector with the given options and returns it.
+func (d *Detector) Init(options Options) *Detector {
+	for _, methodPatterns := range [...][]string{options.SharedMethods, options.ExcludedMethods} {
+		for _, methodPattern := range methodPatterns {
+			if _, err := path.Match(methodPattern, ""); err != nil {
+				panic(errors.New("concurrentuse: invalid method pattern: " + methodPattern))
+			}
+		}
+	}
+
+	if options.Reporter == nil {
+		options.Reporter = func(report *Report) { reporting.WriteToStderr(report) }
+	}
+
+	d.options = options
+	d.callee2InFlight = make(map[interface{}]map[uint64]*callEntry)
+	return d
+}
+
+// Detect adds an interceptor to the given proxy to detect the overlapping
+// calls to the methods of the proxy.
+func (d *Detector) Detect(proxy proxyz.Proxy) {
+	proxyz.InterceptAllMethodCalls(proxy, d.NewMethodCallInterceptor(proxy.XxxUnderlyingType()))
+}
+
+// NewMethodCallInterceptor returns an interceptor detecting the overlapping
+// method calls on the same underlying objects of the given underlying type.
+func (d *Detector) NewMethodCallInterceptor(underlyingType string) proxyz.MethodCallInterceptor {
+	return func(methodCall proxyz.MethodCall) {
+		methodName := methodCall.MethodName()
+
+		if matchMethod(d.options.ExcludedMethods, methodName) {
+			methodCall.Forward()
+			return
+		}
+
+		entry := callEntry{
+			Call: Call{
+				CallID:      methodCall.CallID(),
+				MethodName:  methodName,
+				Args:        proxyz.FormatArgs(methodCall),
+				StartTime:   methodCall.StartTime(),
+				GoroutineID: goroutine.CurrentID(),
+			},
+
+			IsShared: matchMethod(d.options.SharedMethods, methodName),
+		}
+
+		calleeKey := makeCalleeKey(methodCall.Callee())
+		overlappedCall, ok := d.startCall(calleeKey, &entry)
+		defer d.endCall(calleeKey, &entry)
+
+		if ok {
+			call := entry.Call
+			call.Stack = goroutine.CurrentStack()
+			overlappedCall.Stack = goroutine.FindStack(goroutine.AllStacks(), overlappedCall.GoroutineID)
+
+			d.options.Reporter(&Report{
+				UnderlyingType: underlyingType,
+				Call:           call,
+				OverlappedCall: overlappedCall,
+			})
+		}
+
+		methodCall.Forward()
+	}
+}
+
+func (d *Detector) startCall(calleeKey interface{}, entry *callEntry) (Call, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	inFlight, ok := d.callee2InFlight[calleeKey]
+
+	if !ok {
+		inFlight = make(map[uint64]*callEntry)
+		d.callee2InFlight[calleeKey] = inFlight
+	}
+
+	var overlappedEntry *callEntry
+
+	for _, entry2 := range inFlight {
+		if entry2.GoroutineID == entry.GoroutineID || (entry2.IsShared && entry.IsShared) {
+			continue
+		}
+
+		if overlappedEntry == nil || entry2.CallID < overlappedEntry.CallID {
+			overlappedEntry = entry2
+		}
+	}
+
+	inFlight[entry.CallID] = entry
+
+	if overlappedEntry == nil {
+		return Call{}, false
+	}
+
+	return overlappedEntry.Call, true
+}
+
+func (d *Detector) endCall(calleeKey interface{}, entry *callEntry) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	inFlight := d.callee2InFlight[calleeKey]
+	delete(inFlight, entry.CallID)
+
+	if len(inFlight) == 0 {
+		delete(d.callee2InFlight, calleeKey)
+	}
+}
+
+// makeCalleeKey returns a key identifying the given underlying object. The
+// objects of reference kinds are identified by their addresses, the other
+// hashable ones by their values, and the unhashable ones by their types.
+func makeCalleeKey(callee interface{}) interface{} {
+	value := reflect.ValueOf(callee)
+
+	switch value.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return referenceKey{value.Type(), value.Pointer()}
+	}
+
+	if isHashable(callee) {
+		return callee
+	}
+
+	return value.Type()
+}
+
+func isHashable(value interface{}) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+
+	_ = map[interface{}]struct{}{value: {}}
+	return true
+}
+
+type referenceKey struct {
+	Type    reflect.Type
+	Address uintptr
+}
+
+func matchMethod(methodPatterns []string, methodName string) bool {
+	for _, methodPattern := range methodPatterns {
+		if ok, _ := path.Match(methodPattern, methodName); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,82 @@
+package concurrentuse_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roy2220/proxyz"
+	"github.com/roy2220/proxyz/concurrentuse"
+	"github.com/roy2220/proxyz/internal/testproxy"
+)
+
+func TestDetector(t *testing.T) {
+	var reports []*concurrentuse.Report
+	d := new(concurrentuse.Detector).Init(concurrentuse.Options{
+		SharedMethods:   []string{"Get"},
+		ExcludedMethods: []string{"Keys"},
+		Reporter:        func(report *concurrentuse.Report) { reports = append(reports, report) },
+	})
+	kv := testproxy.NewKVProxy(testproxy.MapKV{"foo": "bar"})
+	d.Detect(kv)
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	blocker := func(mc proxyz.MethodCall) {
+		if mc.GetArg(1) == "block" {
+			started <- struct{}{}
+			<-unblock
+		}
+
+		mc.Forward()
+	}
+	kv.XxxInterceptMethodCall(testproxy.KVProxyGet, blocker)
+	kv.XxxInterceptMethodCall(testproxy.KVProxySet, blocker)
+	done := make(chan struct{})
+
+	go func() {
+		kv.Get(context.Background(), "block")
+		done <- struct{}{}
+	}()
+
+	<-started
+	_, err := kv.Get(context.Background(), "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo"}, kv.Keys())
+	assert.Empty(t, reports)
+	err = kv.Set(context.Background(), "foo", "baz")
+	assert.NoError(t, err)
+	require.Len(t, reports, 1)
+	report := reports[0]
+	assert.Equal(t, "github.com/roy2220/proxyz/internal/testproxy.KV", report.UnderlyingType)
+	assert.Equal(t, "Set", report.Call.MethodName)
+	assert.Equal(t, []string{"<context>", "foo", "baz"}, report.Call.Args)
+	assert.Contains(t, report.Call.Stack, "concurrentuse_test.TestDetector")
+	assert.Equal(t, "Get", report.OverlappedCall.MethodName)
+	assert.NotEqual(t, report.Call.GoroutineID, report.OverlappedCall.GoroutineID)
+	assert.Contains(t, report.OverlappedCall.Stack, "concurrentuse_test.TestDetector.func")
+	var buffer bytes.Buffer
+	_, err = report.WriteTo(&buffer)
+	assert.NoError(t, err)
+	assert.Contains(t, buffer.String(), "concurrentuse: overlapping method calls")
+	unblock <- struct{}{}
+	<-done
+
+	reports = nil
+	other := testproxy.NewKVProxy(testproxy.MapKV{})
+	d.Detect(other)
+
+	go func() {
+		kv.Set(context.Background(), "block", "")
+		done <- struct{}{}
+	}()
+
+	<-started
+	err = other.Set(context.Background(), "foo", "bar")
+	assert.NoError(t, err)
+	assert.Empty(t, reports)
+	unblock <- struct{}{}
+	<-done
+}
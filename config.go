@@ -0,0 +1,322 @@
+package proxyz
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// InterceptionConfig represents a declarative config of the built-in
+// behaviors applied to the method calls of all the proxies, e.g.
+//
+//	{
+//	    "rules": [
+//	        {"type": "store.*", "method": "Get*", "action": "log"},
+//	        {"type": "store.KV", "method": "Set", "action": "delay", "delay": "100ms"}
+//	    ]
+//	}
+//
+// The config given by the environment variable PROXYZ_CONFIG, as a file path,
+// is applied at process start.
+type InterceptionConfig struct {
+	Rules []InterceptionRule `json:"rules"`
+}
+
+// InterceptionRule represents a rule of interception configs.
+type InterceptionRule struct {
+	// TypePattern is the pattern, in the syntax of path.Match, of the
+	// underlying types (see Proxy.XxxUnderlyingType) to match, either in the
+	// full form, e.g. "github.com/foo/store.KV", or in the short form without
+	// the directories of the package path, e.g. "store.KV".
+	TypePattern string `json:"type"`
+
+	// MethodPattern is the pattern, in the syntax of path.Match, of the names
+	// of the methods to match. If not specified, all methods will match.
+	MethodPattern string `json:"method"`
+
+	// Action is the behavior applied to the method calls matched, which is
+	// one of ActionLog, ActionDelay, ActionFail and ActionCount.
+	Action string `json:"action"`
+
+	// Delay is the delay, in the syntax of time.ParseDuration, for
+	// ActionDelay.
+	Delay string `json:"delay,omitempty"`
+
+	// Error is the error message for ActionFail. If not specified,
+	// "proxyz: failure injected" will be used.
+	Error string `json:"error,omitempty"`
+}
+
+const (
+	// ActionLog writes the method calls matched to the log writer.
+	ActionLog = "log"
+
+	// ActionDelay delays the method calls matched.
+	ActionDelay = "delay"
+
+	// ActionFail fails the method calls matched, having a trailing error
+	// result, without calling the methods.
+	ActionFail = "fail"
+
+	// ActionCount counts the method calls matched (see InterceptionCounts).
+	ActionCount = "count"
+)
+
+// ReadInterceptionConfig reads an interception config in JSON from the given
+// reader and validates it.
+func ReadInterceptionConfig(reader io.Reader) (*InterceptionConfig, error) {
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+	var config InterceptionConfig
+
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("proxyz: interception config decoding failed: %v", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// LoadInterceptionConfig reads an interception config in JSON from the file
+// with the given path and validates it.
+func LoadInterceptionConfig(filePath string) (*InterceptionConfig, error) {
+	file, err := os.Open(filePath)
+
+	if err != nil {
+		return nil, fmt.Errorf("proxyz: interception config file open failed; filePath=%q: %v", filePath, err)
+	}
+
+	defer file.Close()
+	return ReadInterceptionConfig(file)
+}
+
+// Validate validates the interception config.
+func (ic *InterceptionConfig) Validate() error {
+	for i := range ic.Rules {
+		if err := ic.Rules[i].validate(); err != nil {
+			return fmt.Errorf("proxyz: invalid interception rule; ruleIndex=%d: %v", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (ir *InterceptionRule) validate() error {
+	if _, err := path.Match(ir.TypePattern, ""); err != nil || ir.TypePattern == "" {
+		return fmt.Errorf("invalid type pattern %q", ir.TypePattern)
+	}
+
+	if _, err := path.Match(ir.MethodPattern, ""); err != nil {
+		return fmt.Errorf("invalid method pattern %q", ir.MethodPattern)
+	}
+
+	return ir.validateAction()
+}
+
+func (ir *InterceptionRule) validateAction() error {
+	switch ir.Action {
+	case ActionLog, ActionFail, ActionCount:
+	case ActionDelay:
+		if _, err := time.ParseDuration(ir.Delay); err != nil {
+			return fmt.Errorf("invalid delay %q", ir.Delay)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", ir.Action)
+	}
+
+	return nil
+}
+
+// ApplyInterceptionConfig adds a global interceptor for each rule of the given
+// interception config, in order. The log lines of ActionLog are written to the
+// given writer, or stderr if the writer is nil.
+func ApplyInterceptionConfig(config *InterceptionConfig, logWriter io.Writer) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		methodCallInterceptor, _ := rule.NewMethodCallInterceptor(logWriter)
+
+		AddGlobalInterceptor(newTypeAndMethodFilter(rule.TypePattern, rule.MethodPattern), methodCallInterceptor, InterceptorOptions{
+			Name: "proxyz.config." + strconv.FormatUint(atomic.AddUint64(&lastConfigInterceptorID, 1), 10),
+		})
+	}
+
+	return nil
+}
+
+// NewMethodCallInterceptor returns a method call interceptor performing the
+// action of the rule, regardless of the patterns. The log lines of ActionLog
+// are written to the given writer, or stderr if the writer is nil. The writes
+// of all the log lines are serialized, so the writer needn't be safe for
+// concurrent use.
+func (ir *InterceptionRule) NewMethodCallInterceptor(logWriter io.Writer) (MethodCallInterceptor, error) {
+	if err := ir.validateAction(); err != nil {
+		return nil, fmt.Errorf("proxyz: invalid interception rule: %v", err)
+	}
+
+	switch ir.Action {
+	case ActionLog:
+		if logWriter == nil {
+			logWriter = os.Stderr
+		}
+
+		return newLogInterceptor(logWriter), nil
+	case ActionDelay:
+		delay, _ := time.ParseDuration(ir.Delay)
+		return newDelayInterceptor(delay), nil
+	case ActionFail:
+		errorMessage := ir.Error
+
+		if errorMessage == "" {
+			errorMessage = "proxyz: failure injected"
+		}
+
+		return newFailInterceptor(errors.New(errorMessage)), nil
+	default:
+		return countMethodCall, nil
+	}
+}
+
+var lastConfigInterceptorID uint64
+
+// TraceCalls adds a global interceptor writing the calls to the methods of
+// the proxies, with the underlying types matching the given pattern (see
+// InterceptionRule.TypePattern), to the given writer, or stderr if the writer
+// is nil. It is applied at process start with the pattern given by the
+// environment variable PROXYZ_TRACE.
+func TraceCalls(typePattern string, writer io.Writer) error {
+	return ApplyInterceptionConfig(&InterceptionConfig{
+		Rules: []InterceptionRule{{TypePattern: typePattern, Action: ActionLog}},
+	}, writer)
+}
+
+// InterceptionCounts returns the numbers of the method calls counted by
+// ActionCount, keyed by "UnderlyingType.MethodName".
+func InterceptionCounts() map[string]uint64 {
+	counts := make(map[string]uint64)
+
+	interceptionCounts.Range(func(key, value interface{}) bool {
+		counts[key.(string)] = atomic.LoadUint64(value.(*uint64))
+		return true
+	})
+
+	return counts
+}
+
+// ResetInterceptionCounts clears the numbers of the method calls counted by
+// ActionCount.
+func ResetInterceptionCounts() {
+	interceptionCounts.Range(func(key, _ interface{}) bool {
+		interceptionCounts.Delete(key)
+		return true
+	})
+}
+
+var interceptionCounts sync.Map
+
+func countMethodCall(methodCall MethodCall) {
+	key := methodCall.UnderlyingType() + "." + methodCall.MethodName()
+	value, ok := interceptionCounts.Load(key)
+
+	if !ok {
+		value, _ = interceptionCounts.LoadOrStore(key, new(uint64))
+	}
+
+	atomic.AddUint64(value.(*uint64), 1)
+	methodCall.Forward()
+}
+
+func newLogInterceptor(writer io.Writer) MethodCallInterceptor {
+	return func(methodCall MethodCall) {
+		args := strings.Join(FormatArgs(methodCall), ", ")
+
+		ForwardAndObserve(methodCall, func(outcome Outcome, panicValue interface{}) {
+			var buffer bytes.Buffer
+			fmt.Fprintf(&buffer, "proxyz: method call; callID=%d method=%s.%s args=[%s] duration=%v",
+				methodCall.CallID(), methodCall.UnderlyingType(), methodCall.MethodName(), args,
+				time.Since(methodCall.StartTime()))
+
+			switch outcome {
+			case Returned:
+				fmt.Fprintf(&buffer, " results=[%s]\n", strings.Join(FormatResults(methodCall), ", "))
+			case Panicked:
+				fmt.Fprintf(&buffer, " panic=%q\n", fmt.Sprint(panicValue))
+			default:
+				buffer.WriteString(" goexit\n")
+			}
+
+			logMutex.Lock()
+			writer.Write(buffer.Bytes())
+			logMutex.Unlock()
+		})
+	}
+}
+
+var logMutex sync.Mutex
+
+func newDelayInterceptor(delay time.Duration) MethodCallInterceptor {
+	return func(methodCall MethodCall) {
+		time.Sleep(delay)
+		methodCall.Forward()
+	}
+}
+
+func newFailInterceptor(err error) MethodCallInterceptor {
+	return func(methodCall MethodCall) {
+		if !SetError(methodCall, err) {
+			methodCall.Forward()
+		}
+	}
+}
+
+func newTypeAndMethodFilter(typePattern string, methodPattern string) GlobalInterceptorFilter {
+	return func(underlyingType string, methodName string) bool {
+		if ok, _ := path.Match(typePattern, underlyingType); !ok {
+			if ok, _ := path.Match(typePattern, path.Base(underlyingType)); !ok {
+				return false
+			}
+		}
+
+		if methodPattern == "" {
+			return true
+		}
+
+		ok, _ := path.Match(methodPattern, methodName)
+		return ok
+	}
+}
+
+func init() {
+	if filePath := os.Getenv("PROXYZ_CONFIG"); filePath != "" {
+		config, err := LoadInterceptionConfig(filePath)
+
+		if err == nil {
+			err = ApplyInterceptionConfig(config, nil)
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "proxyz: PROXYZ_CONFIG ignored: %v\n", err)
+		}
+	}
+
+	if typePattern := os.Getenv("PROXYZ_TRACE"); typePattern != "" {
+		if err := TraceCalls(typePattern, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "proxyz: PROXYZ_TRACE ignored: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+// Package coverage provides an interceptor tracking which methods of the
+// proxied types are exercised, and with which classes of arguments and
+// results, e.g. for finding the gaps of integration tests against interfaces.
+//
+// A typical use in tests:
+//
+//	var tracker = new(coverage.Tracker).Init()
+//
+//	func TestMain(m *testing.M) {
+//		code := m.Run()
+//		tracker.WriteReport(os.Stdout)
+//		os.Exit(code)
+//	}
+//
+// with tracker.Track(proxy) called for each proxy under test.
+package coverage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/roy2220/proxyz"
+)
+
+// TypeCoverage represents the coverage of an underlying type.
+type TypeCoverage struct {
+	UnderlyingType string
+
+	// Methods are ordered by the method indexes of the proxy tracked first.
+	Methods []MethodCoverage
+}
+
+// UnexercisedMethods returns the names of the methods never called.
+func (tc *TypeCoverage) UnexercisedMethods() []string {
+	var methodNames []string
+
+	for i := range tc.Methods {
+		if methodCoverage := &tc.Methods[i]; methodCoverage.Calls == 0 {
+			methodNames = append(methodNames, methodCoverage.MethodName)
+		}
+	}
+
+	return methodNames
+}
+
+// MethodCoverage represents the coverage of a method.
+type MethodCoverage struct {
+	MethodName string
+	Calls      uint64
+
+	// Args excludes the context.Context arguments.
+	Args []ArgCoverage
+
+	// HasErrorResult indicates whether the method has a trailing error
+	// result.
+	HasErrorResult bool
+
+	// Errors is the number of the calls returning non-nil errors.
+	Errors uint64
+
+	// NonErrors is the number of the calls returning nil errors.
+	NonErrors uint64
+}
+
+// MissingClasses returns the classes of the arguments and results never
+// exercised, e.g. "key=empty" or "error".
+func (mc *MethodCoverage) MissingClasses() []string {
+	var classes []string
+
+	for i := range mc.Args {
+		argCoverage := &mc.Args[i]
+
+		for _, class := range argCoverage.MissingClasses() {
+			classes = append(classes, argCoverage.ArgName+"="+class)
+		}
+	}
+
+	if mc.HasErrorResult {
+		if mc.Errors == 0 {
+			classes = append(classes, "error")
+		}
+
+		if mc.NonErrors == 0 {
+			classes = append(classes, "no-error")
+		}
+	}
+
+	return classes
+}
+
+// ArgCoverage represents the coverage of an argument.
+type ArgCoverage struct {
+	// ArgName is the name of the argument, or "argN" if unnamed or unknown
+	// as the method is never called.
+	ArgName string
+
+	// IsNilable indicates whether the argument can be nil, which makes the
+	// classes nil and non-nil applicable.
+	IsNilable bool
+
+	// HasLength indicates whether the argument is a string, slice or map,
+	// which makes the classes empty and non-empty applicable.
+	HasLength bool
+
+	Nil      uint64
+	NonNil   uint64
+	Empty    uint64
+	NonEmpty uint64
+}
+
+// MissingClasses returns the applicable classes, among "nil", "non-nil",
+// "empty" and "non-empty", never exercised.
+func (ac *ArgCoverage) MissingClasses() []string {
+	var classes []string
+
+	if ac.IsNilable {
+		if ac.Nil == 0 {
+			classes = append(classes, "nil")
+		}
+
+		if ac.NonNil == 0 {
+			classes = append(classes, "non-nil")
+		}
+	}
+
+	if ac.HasLength {
+		if ac.Empty == 0 {
+			classes = append(classes, "empty")
+		}
+
+		if ac.NonEmpty == 0 {
+			classes = append(classes, "non-empty")
+		}
+	}
+
+	return classes
+}
+
+// Tracker tracks the coverage of the proxied types.
+type Tracker struct {
+	mutex                  sync.Mutex
+	underlyingType2Methods map[string][]*methodCoverage
+}
+
+type methodCoverage struct {
+	MethodCoverage
+
+	ArgIndexes []int
+	IsNamed    bool
+}
+
+// Init initializes the tracker and returns it.
+func (t *Tracker) Init() *Tracker {
+	t.underlyingType2Methods = make(map[string][]*methodCoverage)
+	return t
+}
+
+// Track tracks the calls to all the methods of the given proxy. The coverage
+// of the proxies with the same underlying type is merged.
+func (t *Tracker) Track(proxy proxyz.Proxy) {
+	underlyingType := proxy.XxxUnderlyingType()
+	proxyValue := reflect.ValueOf(proxy)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	methodCoverages := t.underlyingType2Methods[underlyingType]
+
+	for methodIndex, n := 0, proxy.XxxNumberOfMethods(); methodIndex < n; methodIndex++ {
+		methodName := proxy.XxxGetMethodName(methodIndex)
+		var methodCoverage1 *methodCoverage
+
+		for _, methodCoverage2 := range methodCoverages {
+			if methodCoverage2.MethodName == methodName {
+				methodCoverage1 = methodCoverage2
+				break
+			}
+		}
+
+		if methodCoverage1 == nil {
+			methodCoverage1 = newMethodCoverage(methodName, proxyValue.MethodByName(methodName).Type())
+			methodCoverages = append(methodCoverages, methodCoverage1)
+		}
+
+		proxy.XxxInterceptMethodCall(methodIndex, t.newMethodCallInterceptor(methodCoverage1))
+	}
+
+	t.underlyingType2Methods[underlyingType] = methodCoverages
+}
+
+// Coverage returns the coverage of all the types tracked, ordered by the
+// underlying types.
+func (t *Tracker) Coverage() []TypeCoverage {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	typeCoverages := make([]TypeCoverage, 0, len(t.underlyingType2Methods))
+
+	for underlyingType, methodCoverages := range t.underlyingType2Methods {
+		typeCoverage := TypeCoverage{
+			UnderlyingType: underlyingType,
+			Methods:        make([]MethodCoverage, len(methodCoverages)),
+		}
+
+		for i, methodCoverage := range methodCoverages {
+			typeCoverage.Methods[i] = methodCoverage.MethodCoverage
+			typeCoverage.Methods[i].Args = append([]ArgCoverage(nil), methodCoverage.Args...)
+		}
+
+		typeCoverages = append(typeCoverages, typeCoverage)
+	}
+
+	sort.Slice(typeCoverages, func(i, j int) bool {
+		return typeCoverages[i].UnderlyingType < typeCoverages[j].UnderlyingType
+	})
+
+	return typeCoverages
+}
+
+// WriteReport writes the coverage of all the types tracked in text to the
+// given writer, e.g.
+//
+//	github.com/foo/store.KV: 2/3 methods exercised (66.7%)
+//		Get: calls=3 missing=[key=empty]
+//		Keys: NOT EXERCISED
+//		Set: calls=1 missing=[error]
+func (t *Tracker) WriteReport(writer io.Writer) error {
+	var buffer bytes.Buffer
+
+	for _, typeCoverage := range t.Coverage() {
+		n := len(typeCoverage.Methods)
+		m := n - len(typeCoverage.UnexercisedMethods())
+		percentage := 100.0
+
+		if n >= 1 {
+			percentage = 100 * float64(m) / float64(n)
+		}
+
+		fmt.Fprintf(&buffer, "%s: %d/%d methods exercised (%.1f%%)\n", typeCoverage.UnderlyingType, m, n, percentage)
+
+		for i := range typeCoverage.Methods {
+			methodCoverage := &typeCoverage.Methods[i]
+
+			if methodCoverage.Calls == 0 {
+				fmt.Fprintf(&buffer, "\t%s: NOT EXERCISED\n", methodCoverage.MethodName)
+				continue
+			}
+
+			fmt.Fprintf(&buffer, "\t%s: calls=%d", methodCoverage.MethodName, methodCoverage.Calls)
+
+			if classes := methodCoverage.MissingClasses(); len(classes) >= 1 {
+				fmt.Fprintf(&buffer, " missing=[%s]", strings.Join(classes, ", "))
+			}
+
+			buffer.WriteByte('\n')
+		}
+	}
+
+	_, err := buffer.WriteTo(writer)
+	return err
+}
+
+func (t *Tracker) newMethodCallInterceptor(methodCoverage *methodCoverage) proxyz.MethodCallInterceptor {
+	return func(methodCall proxyz.MethodCall) {
+		t.mutex.Lock()
+		methodCoverage.Calls++
+
+		if !methodCoverage.IsNamed {
+			methodCoverage.IsNamed = true
+
+			for i, argIndex := range methodCoverage.ArgIndexes {
+				if argName := methodCall.ArgName(argIndex); argName != "" {
+					methodCoverage.Args[i].ArgName = argName
+				}
+			}
+		}
+
+		for i, argIndex := range methodCoverage.ArgIndexes {
+			methodCoverage.Args[i].update(methodCall.GetArg(argIndex))
+		}
+
+		t.mutex.Unlock()
+		methodCall.Forward()
+
+		if methodCoverage.HasErrorResult {
+			t.mutex.Lock()
+
+			if proxyz.GetError(methodCall) == nil {
+				methodCoverage.NonErrors++
+			} else {
+				methodCoverage.Errors++
+			}
+
+			t.mutex.Unlock()
+		}
+	}
+}
+
+func newMethodCoverage(methodName string, methodType reflect.Type) *methodCoverage {
+	methodCoverage := &methodCoverage{MethodCoverage: MethodCoverage{MethodName: methodName}}
+
+	for argIndex, n := 0, methodType.NumIn(); argIndex < n; argIndex++ {
+		argType := methodType.In(argIndex)
+
+		if argType == contextType {
+			continue
+		}
+
+		methodCoverage.ArgIndexes = append(methodCoverage.ArgIndexes, argIndex)
+		argKind := argType.Kind()
+
+		methodCoverage.Args = append(methodCoverage.Args, ArgCoverage{
+			ArgName:   "arg" + strconv.Itoa(argIndex),
+			IsNilable: isNilableKind(argKind),
+			HasLength: argKind == reflect.String || argKind == reflect.Slice || argKind == reflect.Map,
+		})
+	}
+
+	if n := methodType.NumOut(); n >= 1 && methodType.Out(n-1) == errorType {
+		methodCoverage.HasErrorResult = true
+	}
+
+	return methodCoverage
+}
+
+func (ac *ArgCoverage) update(arg interface{}) {
+	argValue := reflect.ValueOf(arg)
+
+	if ac.IsNilable {
+		if arg == nil || (isNilableKind(argValue.Kind()) && argValue.IsNil()) {
+			ac.Nil++
+		} else {
+			ac.NonNil++
+		}
+	}
+
+	if ac.HasLength {
+		if argValue.Len() == 0 {
+			ac.Empty++
+		} else {
+			ac.NonEmpty++
+		}
+	}
+}
+
+func isNilableKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice, reflect.UnsafePointer:
+		return true
+	default:
+		return false
+	}
+}
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
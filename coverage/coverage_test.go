@@ -0,0 +1,55 @@
+package coverage_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roy2220/proxyz/coverage"
+	"github.com/roy2220/proxyz/internal/testproxy"
+)
+
+func TestTracker(t *testing.T) {
+	tracker := new(coverage.Tracker).Init()
+	kv := testproxy.NewKVProxy(testproxy.MapKV{})
+	tracker.Track(kv)
+	tracker.Track(testproxy.NewKVProxy(testproxy.MapKV{}))
+	accountStore := testproxy.NewAccountStoreProxy(testproxy.MapAccountStore{})
+	tracker.Track(accountStore)
+	ctx := context.Background()
+
+	kv.Set(ctx, "foo", "")
+	kv.Get(ctx, "foo")
+	kv.Get(ctx, "bar")
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	accountStore.CreateAccount(cancelledCtx, nil)
+
+	typeCoverages := tracker.Coverage()
+	require.Len(t, typeCoverages, 2)
+	assert.Equal(t, "github.com/roy2220/proxyz/internal/testproxy.AccountStore", typeCoverages[0].UnderlyingType)
+	assert.Equal(t, []string{"CountAccounts", "Login"}, typeCoverages[0].UnexercisedMethods())
+	kvCoverage := typeCoverages[1]
+	assert.Equal(t, []string{"Keys"}, kvCoverage.UnexercisedMethods())
+	getCoverage := kvCoverage.Methods[testproxy.KVProxyGet]
+	assert.Equal(t, uint64(2), getCoverage.Calls)
+	assert.Equal(t, []coverage.ArgCoverage{{ArgName: "key", HasLength: true, NonEmpty: 2}}, getCoverage.Args)
+	assert.Equal(t, []string{"key=empty"}, getCoverage.MissingClasses())
+	setCoverage := kvCoverage.Methods[testproxy.KVProxySet]
+	assert.Equal(t, []string{"key=empty", "value=non-empty", "error"}, setCoverage.MissingClasses())
+
+	var buffer bytes.Buffer
+	require.NoError(t, tracker.WriteReport(&buffer))
+	assert.Equal(t, `github.com/roy2220/proxyz/internal/testproxy.AccountStore: 1/3 methods exercised (33.3%)
+	CountAccounts: NOT EXERCISED
+	CreateAccount: calls=1 missing=[account=non-nil, no-error]
+	Login: NOT EXERCISED
+github.com/roy2220/proxyz/internal/testproxy.KV: 2/3 methods exercised (66.7%)
+	Get: calls=2 missing=[key=empty]
+	Keys: NOT EXERCISED
+	Set: calls=1 missing=[key=empty, value=non-empty, error]
+`, buffer.String())
+}
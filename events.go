@@ -0,0 +1,231 @@
+package proxyz
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CallEvent represents an event of a completed method call. It should be
+// treated as immutable, as it is shared among the observers.
+type CallEvent struct {
+	CallID         uint64
+	UnderlyingType string
+	MethodName     string
+
+	// Args are the arguments of the method call formatted by FormatArg.
+	Args []string
+
+	// Results are the results of the method call formatted by fmt.Sprintf
+	// with "%v", or nil if the method call didn't return.
+	Results []string
+
+	StartTime time.Time
+	Duration  time.Duration
+
+	// Err is the trailing error result of the method call, if any.
+	Err error
+
+	// PanicValue is the value, formatted by fmt.Sprint, which the method call
+	// panicked with, if any.
+	PanicValue string
+
+	// IsGoexited indicates whether the goroutine exited through
+	// runtime.Goexit during the method call.
+	IsGoexited bool
+}
+
+// DropPolicy is the policy of dropping events when the buffer of a
+// subscription is full.
+type DropPolicy int
+
+const (
+	// DropNewest drops the event being published.
+	DropNewest DropPolicy = iota
+
+	// DropOldest drops the oldest event buffered to make room for the event
+	// being published.
+	DropOldest
+)
+
+// SubscriptionOptions represents options for subscriptions.
+type SubscriptionOptions struct {
+	// BufferSize is the maximum number of the events buffered for the
+	// observer. If not specified, 1024 will be used.
+	BufferSize int
+
+	// DropPolicy is the policy of dropping events when the buffer is full.
+	DropPolicy DropPolicy
+}
+
+// EventBus delivers the events of the method calls of the proxies attached
+// (see Proxy.XxxSetEventBus) to the observers subscribed, asynchronously, so
+// that slow observers never add latency to the method calls.
+type EventBus struct {
+	mutex         sync.Mutex
+	subscriptions atomic.Value
+}
+
+// Init initializes the event bus and returns it.
+func (eb *EventBus) Init() *EventBus {
+	eb.subscriptions.Store([]*Subscription(nil))
+	return eb
+}
+
+// Subscribe subscribes the given observer with the given options. The
+// observer is called in a dedicated goroutine, one event at a time.
+func (eb *EventBus) Subscribe(observer func(event *CallEvent), options SubscriptionOptions) *Subscription {
+	if options.BufferSize <= 0 {
+		options.BufferSize = 1024
+	}
+
+	subscription := &Subscription{
+		eventBus:   eb,
+		dropPolicy: options.DropPolicy,
+		events:     make(chan *CallEvent, options.BufferSize),
+		done:       make(chan struct{}),
+	}
+
+	go func() {
+		defer close(subscription.done)
+
+		for event := range subscription.events {
+			observer(event)
+		}
+	}()
+
+	eb.mutex.Lock()
+	defer eb.mutex.Unlock()
+	oldSubscriptions := eb.loadSubscriptions()
+	newSubscriptions := make([]*Subscription, len(oldSubscriptions), len(oldSubscriptions)+1)
+	copy(newSubscriptions, oldSubscriptions)
+	eb.subscriptions.Store(append(newSubscriptions, subscription))
+	return subscription
+}
+
+// Publish publishes the given event to all the observers subscribed without
+// blocking.
+func (eb *EventBus) Publish(event *CallEvent) {
+	for _, subscription := range eb.loadSubscriptions() {
+		subscription.deliver(event)
+	}
+}
+
+// Close unsubscribes all the observers subscribed.
+func (eb *EventBus) Close() {
+	for _, subscription := range eb.loadSubscriptions() {
+		subscription.Unsubscribe()
+	}
+}
+
+func (eb *EventBus) removeSubscription(subscription *Subscription) {
+	eb.mutex.Lock()
+	defer eb.mutex.Unlock()
+	oldSubscriptions := eb.loadSubscriptions()
+	newSubscriptions := make([]*Subscription, 0, len(oldSubscriptions))
+
+	for _, subscription2 := range oldSubscriptions {
+		if subscription2 != subscription {
+			newSubscriptions = append(newSubscriptions, subscription2)
+		}
+	}
+
+	eb.subscriptions.Store(newSubscriptions)
+}
+
+func (eb *EventBus) loadSubscriptions() []*Subscription {
+	subscriptions, _ := eb.subscriptions.Load().([]*Subscription)
+	return subscriptions
+}
+
+func (eb *EventBus) newMethodCallInterceptor(underlyingType string) MethodCallInterceptor {
+	return func(methodCall MethodCall) {
+		event := CallEvent{
+			CallID:         methodCall.CallID(),
+			UnderlyingType: underlyingType,
+			MethodName:     methodCall.MethodName(),
+			Args:           FormatArgs(methodCall),
+			StartTime:      methodCall.StartTime(),
+		}
+
+		ForwardAndObserve(methodCall, func(outcome Outcome, panicValue interface{}) {
+			event.Duration = time.Since(event.StartTime)
+
+			switch outcome {
+			case Returned:
+				event.Results = FormatResults(methodCall)
+				event.Err = GetError(methodCall)
+			case Panicked:
+				event.PanicValue = fmt.Sprint(panicValue)
+			default:
+				event.IsGoexited = true
+			}
+
+			eb.Publish(&event)
+		})
+	}
+}
+
+// Subscription represents a subscription of an observer to an event bus.
+type Subscription struct {
+	eventBus     *EventBus
+	dropPolicy   DropPolicy
+	mutex        sync.RWMutex
+	events       chan *CallEvent
+	isClosed     bool
+	done         chan struct{}
+	droppedCount uint64
+}
+
+// Unsubscribe unsubscribes the observer and waits for the observer to
+// consume the events buffered.
+func (s *Subscription) Unsubscribe() {
+	s.eventBus.removeSubscription(s)
+	s.mutex.Lock()
+
+	if !s.isClosed {
+		s.isClosed = true
+		close(s.events)
+	}
+
+	s.mutex.Unlock()
+	<-s.done
+}
+
+// DroppedCount returns the number of the events dropped as the buffer was
+// full.
+func (s *Subscription) DroppedCount() uint64 {
+	return atomic.LoadUint64(&s.droppedCount)
+}
+
+func (s *Subscription) deliver(event *CallEvent) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.isClosed {
+		return
+	}
+
+	select {
+	case s.events <- event:
+		return
+	default:
+	}
+
+	if s.dropPolicy == DropOldest {
+		select {
+		case <-s.events:
+			atomic.AddUint64(&s.droppedCount, 1)
+		default:
+		}
+
+		select {
+		case s.events <- event:
+			return
+		default:
+		}
+	}
+
+	atomic.AddUint64(&s.droppedCount, 1)
+}
@@ -21,6 +21,8 @@ func newCalcProxy(underlying *calc) *calcProxy {
 const calcProxySum = 0
 
 type calcProxySumCall struct {
+	proxyz.XxxMethodCallBase
+
 	Arg0    int
 	Arg1    int
 	Result0 string
@@ -55,9 +57,17 @@ func (mc *calcProxySumCall) GetArg(argIndex int) interface{} {
 func (mc *calcProxySumCall) SetArg(argIndex int, arg interface{}) {
 	switch argIndex {
 	case 0:
-		mc.Arg0 = arg.(int)
+		if arg == nil {
+			mc.Arg0 = *new(int)
+		} else {
+			mc.Arg0 = arg.(int)
+		}
 	case 1:
-		mc.Arg1 = arg.(int)
+		if arg == nil {
+			mc.Arg1 = *new(int)
+		} else {
+			mc.Arg1 = arg.(int)
+		}
 	default:
 		panic("arg index out of range")
 	}
@@ -75,16 +85,44 @@ func (mc *calcProxySumCall) GetResult(resultIndex int) interface{} {
 func (mc *calcProxySumCall) SetResult(resultIndex int, result interface{}) {
 	switch resultIndex {
 	case 0:
-		mc.Result0 = result.(string)
+		if result == nil {
+			mc.Result0 = *new(string)
+		} else {
+			mc.Result0 = result.(string)
+		}
 	default:
 		panic("result index out of range")
 	}
 }
 
-func (mc *calcProxySumCall) MethodName() string   { return "Sum" }
-func (mc *calcProxySumCall) MethodIndex() int     { return calcProxySum }
-func (mc *calcProxySumCall) NumberOfArgs() int    { return 2 }
-func (mc *calcProxySumCall) NumberOfResults() int { return 1 }
+func (mc *calcProxySumCall) UnderlyingType() string {
+	return "github.com/roy2220/proxyz/examples/calc.calc"
+}
+func (mc *calcProxySumCall) MethodName() string    { return "Sum" }
+func (mc *calcProxySumCall) MethodIndex() int      { return calcProxySum }
+func (mc *calcProxySumCall) NumberOfArgs() int     { return 2 }
+func (mc *calcProxySumCall) NumberOfResults() int  { return 1 }
+func (mc *calcProxySumCall) ContextArgIndex() int  { return -1 }
+func (mc *calcProxySumCall) ErrorResultIndex() int { return -1 }
+
+func (mc *calcProxySumCall) ArgName(argIndex int) string {
+	return [...]string{"x", "y"}[argIndex]
+}
+
+func (mc *calcProxySumCall) IsArgSensitive(argIndex int) bool {
+	return false
+}
+
+func (mc *calcProxySumCall) String() string { return proxyz.FormatMethodCall(mc) }
+
+func (mc *calcProxySumCall) Callee() interface{}          { return mc.callee }
+func (mc *calcProxySumCall) SetCallee(callee interface{}) { mc.callee = callee.(*calc) }
+
+func (mc *calcProxySumCall) Clone() proxyz.MethodCall {
+	clone := *mc
+	clone.XxxInitClone(&clone)
+	return &clone
+}
 
 func (mc *calcProxySumCall) getNextInterceptor() (proxyz.MethodCallInterceptor, bool) {
 	if i := mc.nextInterceptorIndex; i < len(mc.interceptors) {
@@ -96,7 +134,7 @@ func (mc *calcProxySumCall) getNextInterceptor() (proxyz.MethodCallInterceptor,
 }
 
 func (p *calcProxy) Sum(_x_ int, _y_ int) string {
-	methodCallInterceptors := p.XxxGetMethodCallInterceptors(calcProxySum)
+	methodCallInterceptors := p.XxxResolveMethodCallInterceptors(p, calcProxySum)
 
 	if len(methodCallInterceptors) == 0 {
 		return p.calc.Sum(_x_, _y_)
@@ -110,6 +148,7 @@ func (p *calcProxy) Sum(_x_ int, _y_ int) string {
 		interceptors: methodCallInterceptors,
 	}
 
+	methodCall.XxxInit(&methodCall)
 	methodCall.Forward()
 	return methodCall.Result0
 }
@@ -122,3 +161,8 @@ func (p *calcProxy) XxxGetMethodName(methodIndex int) string {
 
 func (p *calcProxy) XxxNumberOfMethods() int   { return 1 }
 func (p *calcProxy) XxxUnderlyingType() string { return "github.com/roy2220/proxyz/examples/calc.calc" }
+
+func (p *calcProxy) XxxIsOfUnderlyingType(value interface{}) bool {
+	_, ok := value.(*calc)
+	return ok
+}
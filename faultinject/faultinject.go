@@ -0,0 +1,180 @@
+// Package faultinject provides an interceptor injecting faults into method
+// calls for chaos testing.
+package faultinject
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/roy2220/proxyz"
+)
+
+// Fault describes a fault to inject.
+type Fault struct {
+	// MethodPattern is the pattern, in the syntax of path.Match, of the
+	// names of the methods to inject the fault into. An empty pattern
+	// matches all methods.
+	MethodPattern string
+
+	// Probability is the probability, from 0 to 1, of injecting the fault
+	// into a method call.
+	Probability float64
+
+	// Latency is the delay added before the method call is forwarded.
+	Latency time.Duration
+
+	// Error, if not nil, is set as the trailing error result of the method
+	// call, which will NOT be forwarded. Methods without a trailing error
+	// result are not affected.
+	Error error
+
+	// PanicValue, if not nil, is the value to panic with.
+	PanicValue interface{}
+}
+
+// Options represents options for injectors.
+type Options struct {
+	// Seed is the seed of the random number generator deciding whether a
+	// fault should be injected, which makes the injections reproducible.
+	Seed int64
+
+	// Faults are the faults to inject, in order.
+	Faults []Fault
+
+	// MarkedOnly indicates whether to inject faults only into the method
+	// calls whose context.Context arguments carry a marker.
+	// See WithMarker.
+	MarkedOnly bool
+
+	// Disabled indicates whether the injector is initially disabled.
+	Disabled bool
+}
+
+// Injector injects faults into method calls.
+type Injector struct {
+	options  Options
+	disabled int32
+	mutex    sync.Mutex
+	rand     *rand.Rand
+}
+
+// Init initializes the injector with the given options and returns it.
+func (i *Injector) Init(options Options) *Injector {
+	for _, fault := range options.Faults {
+		if _, err := path.Match(fault.MethodPattern, ""); err != nil {
+			panic(errors.New("faultinject: invalid method pattern: " + fault.MethodPattern))
+		}
+	}
+
+	i.options = options
+
+	if options.Disabled {
+		i.disabled = 1
+	}
+
+	i.rand = rand.New(rand.NewSource(options.Seed))
+	return i
+}
+
+// Enable enables the injector.
+func (i *Injector) Enable() { atomic.StoreInt32(&i.disabled, 0) }
+
+// Disable disables the injector, method calls will be forwarded as is.
+func (i *Injector) Disable() { atomic.StoreInt32(&i.disabled, 1) }
+
+// IsEnabled returns whether the injector is enabled.
+func (i *Injector) IsEnabled() bool { return atomic.LoadInt32(&i.disabled) == 0 }
+
+// InterceptMethodCall is a proxyz.MethodCallInterceptor injecting faults.
+func (i *Injector) InterceptMethodCall(methodCall proxyz.MethodCall) {
+	if !i.IsEnabled() {
+		methodCall.Forward()
+		return
+	}
+
+	ctx, _ := proxyz.GetContext(methodCall)
+
+	if i.options.MarkedOnly && !HasMarker(ctx) {
+		methodCall.Forward()
+		return
+	}
+
+	for j := range i.options.Faults {
+		fault := &i.options.Faults[j]
+
+		if !fault.matchMethod(methodCall.MethodName()) || !i.roll(fault.Probability) {
+			continue
+		}
+
+		if fault.Latency >= 1 {
+			sleep(ctx, fault.Latency)
+		}
+
+		if fault.PanicValue != nil {
+			panic(fault.PanicValue)
+		}
+
+		if fault.Error != nil && proxyz.SetError(methodCall, fault.Error) {
+			return
+		}
+	}
+
+	methodCall.Forward()
+}
+
+func (i *Injector) roll(probability float64) bool {
+	if probability <= 0 {
+		return false
+	}
+
+	i.mutex.Lock()
+	x := i.rand.Float64()
+	i.mutex.Unlock()
+	return x < probability
+}
+
+// ErrInjected is a convenient error for Fault.Error.
+var ErrInjected = errors.New("faultinject: fault injected")
+
+// WithMarker returns a copy of the given context carrying a marker, so that
+// the faults can be injected into the method calls with the context when
+// Options.MarkedOnly is set.
+func WithMarker(ctx context.Context) context.Context {
+	return context.WithValue(ctx, markerKey{}, struct{}{})
+}
+
+// HasMarker returns whether the given context carries a marker.
+func HasMarker(ctx context.Context) bool {
+	return ctx != nil && ctx.Value(markerKey{}) != nil
+}
+
+type markerKey struct{}
+
+func (f *Fault) matchMethod(methodName string) bool {
+	if f.MethodPattern == "" {
+		return true
+	}
+
+	ok, _ := path.Match(f.MethodPattern, methodName)
+	return ok
+}
+
+func sleep(ctx context.Context, duration time.Duration) {
+	if ctx == nil {
+		time.Sleep(duration)
+		return
+	}
+
+	timer := time.NewTimer(duration)
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		timer.Stop()
+	}
+}
@@ -0,0 +1,92 @@
+package faultinject_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/roy2220/proxyz"
+	"github.com/roy2220/proxyz/faultinject"
+	"github.com/roy2220/proxyz/internal/testproxy"
+)
+
+func TestInjectorError(t *testing.T) {
+	kv := testproxy.NewKVProxy(testproxy.MapKV{"foo": "bar"})
+	injector := new(faultinject.Injector).Init(faultinject.Options{
+		Faults: []faultinject.Fault{
+			{MethodPattern: "Get", Probability: 1, Error: faultinject.ErrInjected},
+			{MethodPattern: "K*", Probability: 1, Error: faultinject.ErrInjected},
+		},
+	})
+	proxyz.InterceptAllMethodCalls(kv, injector.InterceptMethodCall)
+	ctx := context.Background()
+
+	_, err := kv.Get(ctx, "foo")
+	assert.Equal(t, faultinject.ErrInjected, err)
+	assert.NoError(t, kv.Set(ctx, "foo", "baz"))
+	assert.Equal(t, []string{"foo"}, kv.Keys())
+
+	injector.Disable()
+	assert.False(t, injector.IsEnabled())
+	v, err := kv.Get(ctx, "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "baz", v)
+
+	injector.Enable()
+	_, err = kv.Get(ctx, "foo")
+	assert.Equal(t, faultinject.ErrInjected, err)
+}
+
+func TestInjectorLatencyAndPanic(t *testing.T) {
+	kv := testproxy.NewKVProxy(testproxy.MapKV{})
+	injector := new(faultinject.Injector).Init(faultinject.Options{
+		Faults: []faultinject.Fault{
+			{MethodPattern: "Set", Probability: 1, Latency: 20 * time.Millisecond},
+			{MethodPattern: "Keys", Probability: 1, PanicValue: "boom"},
+		},
+	})
+	proxyz.InterceptAllMethodCalls(kv, injector.InterceptMethodCall)
+
+	t0 := time.Now()
+	assert.NoError(t, kv.Set(context.Background(), "foo", "bar"))
+	assert.GreaterOrEqual(t, int64(time.Since(t0)), int64(20*time.Millisecond))
+	assert.PanicsWithValue(t, "boom", func() { kv.Keys() })
+}
+
+func TestInjectorMarkedOnly(t *testing.T) {
+	kv := testproxy.NewKVProxy(testproxy.MapKV{})
+	injector := new(faultinject.Injector).Init(faultinject.Options{
+		Faults:     []faultinject.Fault{{Probability: 1, Error: faultinject.ErrInjected}},
+		MarkedOnly: true,
+	})
+	proxyz.InterceptAllMethodCalls(kv, injector.InterceptMethodCall)
+	ctx := context.Background()
+
+	assert.NoError(t, kv.Set(ctx, "foo", "bar"))
+	assert.Equal(t, faultinject.ErrInjected, kv.Set(faultinject.WithMarker(ctx), "foo", "bar"))
+}
+
+func TestInjectorSeed(t *testing.T) {
+	run := func() []bool {
+		kv := testproxy.NewKVProxy(testproxy.MapKV{})
+		injector := new(faultinject.Injector).Init(faultinject.Options{
+			Seed:   2020,
+			Faults: []faultinject.Fault{{Probability: 0.5, Error: faultinject.ErrInjected}},
+		})
+		proxyz.InterceptAllMethodCalls(kv, injector.InterceptMethodCall)
+		var outcomes []bool
+
+		for i := 0; i < 100; i++ {
+			outcomes = append(outcomes, kv.Set(context.Background(), "foo", "bar") != nil)
+		}
+
+		return outcomes
+	}
+
+	outcomes := run()
+	assert.Equal(t, outcomes, run())
+	assert.Contains(t, outcomes, true)
+	assert.Contains(t, outcomes, false)
+}
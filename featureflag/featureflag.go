@@ -0,0 +1,247 @@
+// Package featureflag provides an interceptor routing method calls by feature
+// flags, and a combinator gating other interceptors by feature flags.
+package featureflag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/roy2220/proxyz"
+)
+
+// Provider is the interface of pluggable feature flag providers.
+type Provider interface {
+	// IsEnabled reports whether the flag with the given name is on. The
+	// given context is the context.Context argument of the method call, or
+	// context.Background() if the method has no such argument, for
+	// per-request targeting.
+	IsEnabled(ctx context.Context, flagName string) bool
+}
+
+// ProviderFunc is the type of function implementing Provider.
+type ProviderFunc func(ctx context.Context, flagName string) bool
+
+var _ Provider = ProviderFunc(nil)
+
+// IsEnabled implements Provider.IsEnabled.
+func (pf ProviderFunc) IsEnabled(ctx context.Context, flagName string) bool { return pf(ctx, flagName) }
+
+// StaticProvider is a Provider holding the flags in memory. The zero value
+// is ready to use, with all the flags off.
+type StaticProvider struct {
+	mutex sync.RWMutex
+	flags map[string]bool
+}
+
+var _ Provider = (*StaticProvider)(nil)
+
+// Init initializes the provider with the given initial flags and returns it.
+func (sp *StaticProvider) Init(flags map[string]bool) *StaticProvider {
+	sp.mutex.Lock()
+	defer sp.mutex.Unlock()
+	sp.flags = make(map[string]bool, len(flags))
+
+	for flagName, isEnabled := range flags {
+		sp.flags[flagName] = isEnabled
+	}
+
+	return sp
+}
+
+// Set turns the flag with the given name on or off.
+func (sp *StaticProvider) Set(flagName string, isEnabled bool) {
+	sp.mutex.Lock()
+	defer sp.mutex.Unlock()
+
+	if sp.flags == nil {
+		sp.flags = make(map[string]bool)
+	}
+
+	sp.flags[flagName] = isEnabled
+}
+
+// IsEnabled implements Provider.IsEnabled.
+func (sp *StaticProvider) IsEnabled(_ context.Context, flagName string) bool {
+	sp.mutex.RLock()
+	defer sp.mutex.RUnlock()
+	return sp.flags[flagName]
+}
+
+// WithFlag returns a copy of the given context overriding the flag with the
+// given name for the requests carrying it. See ContextProvider.
+func WithFlag(ctx context.Context, flagName string, isEnabled bool) context.Context {
+	oldFlags, _ := ctx.Value(contextFlagsKey{}).(map[string]bool)
+	newFlags := make(map[string]bool, len(oldFlags)+1)
+
+	for flagName2, isEnabled2 := range oldFlags {
+		newFlags[flagName2] = isEnabled2
+	}
+
+	newFlags[flagName] = isEnabled
+	return context.WithValue(ctx, contextFlagsKey{}, newFlags)
+}
+
+// ContextProvider returns a Provider resolving the flags from the overrides
+// carried by the context (see WithFlag) first, and then from the given
+// fallback provider, which can be nil.
+func ContextProvider(fallback Provider) Provider {
+	return ProviderFunc(func(ctx context.Context, flagName string) bool {
+		flags, _ := ctx.Value(contextFlagsKey{}).(map[string]bool)
+
+		if isEnabled, ok := flags[flagName]; ok {
+			return isEnabled
+		}
+
+		if fallback == nil {
+			return false
+		}
+
+		return fallback.IsEnabled(ctx, flagName)
+	})
+}
+
+type contextFlagsKey struct{}
+
+// IsEnabled returns a proxyz.MethodCallPredicate reporting whether the flag
+// with the given name is on for the method call.
+func IsEnabled(provider Provider, flagName string) proxyz.MethodCallPredicate {
+	return func(methodCall proxyz.MethodCall) bool {
+		return provider.IsEnabled(getContext(methodCall), flagName)
+	}
+}
+
+// Gate returns an interceptor calling the given interceptor only while the
+// flag with the given name is on, and forwarding the method calls otherwise.
+func Gate(provider Provider, flagName string, methodCallInterceptor proxyz.MethodCallInterceptor) proxyz.MethodCallInterceptor {
+	return proxyz.When(IsEnabled(provider, flagName), methodCallInterceptor)
+}
+
+// Target represents where a method call is routed to.
+type Target int
+
+const (
+	// Underlying is the underlying object of the proxy.
+	Underlying Target = iota
+
+	// Alternate is the alternate implementation.
+	Alternate
+
+	// Stub is a stub returning zero values along with the stub error, if the
+	// method has a trailing error result, without calling any
+	// implementation.
+	Stub
+)
+
+// String returns the representation of the target.
+func (t Target) String() string {
+	switch t {
+	case Underlying:
+		return "underlying"
+	case Alternate:
+		return "alternate"
+	case Stub:
+		return "stub"
+	default:
+		return fmt.Sprintf("Target(%d)", int(t))
+	}
+}
+
+// ErrStubbed is the default stub error.
+var ErrStubbed = errors.New("featureflag: method stubbed")
+
+// Options represents options for routers.
+type Options struct {
+	// FlagName is the name of the flag consulted for the calls to all
+	// methods. If not specified, only the methods in MethodFlagNames are
+	// routed by flags, the others are always routed to Underlying.
+	FlagName string
+
+	// MethodFlagNames are the names of the flags per method, overriding
+	// FlagName.
+	MethodFlagNames map[string]string
+
+	// OnTarget is the target while the flag is on.
+	OnTarget Target
+
+	// OffTarget is the target while the flag is off.
+	OffTarget Target
+
+	// Alternate is the alternate implementation, which must be of the
+	// underlying type of the proxy. It is required if either target is
+	// Alternate.
+	Alternate interface{}
+
+	// StubError is the error returned by Stub. If not specified, ErrStubbed
+	// will be used.
+	StubError error
+}
+
+// Router routes method calls by feature flags.
+type Router struct {
+	provider Provider
+	options  Options
+}
+
+// Init initializes the router, for the method calls of the given proxy, with
+// the given provider and options and returns it.
+func (r *Router) Init(proxy proxyz.Proxy, provider Provider, options Options) *Router {
+	if options.Alternate == nil && (options.OnTarget == Alternate || options.OffTarget == Alternate) {
+		panic(errors.New("featureflag: alternate implementation required"))
+	}
+
+	if options.Alternate != nil && !proxy.XxxIsOfUnderlyingType(options.Alternate) {
+		panic(fmt.Errorf("featureflag: alternate implementation of wrong type; underlyingType=%q alternateType=%T",
+			proxy.XxxUnderlyingType(), options.Alternate))
+	}
+
+	if options.StubError == nil {
+		options.StubError = ErrStubbed
+	}
+
+	r.provider = provider
+	r.options = options
+	return r
+}
+
+// Route returns the target which the given method call is routed to.
+func (r *Router) Route(methodCall proxyz.MethodCall) Target {
+	flagName, ok := r.options.MethodFlagNames[methodCall.MethodName()]
+
+	if !ok {
+		flagName = r.options.FlagName
+
+		if flagName == "" {
+			return Underlying
+		}
+	}
+
+	if r.provider.IsEnabled(getContext(methodCall), flagName) {
+		return r.options.OnTarget
+	}
+
+	return r.options.OffTarget
+}
+
+// InterceptMethodCall is a proxyz.MethodCallInterceptor routing the method
+// call to the target decided by the flag.
+func (r *Router) InterceptMethodCall(methodCall proxyz.MethodCall) {
+	switch r.Route(methodCall) {
+	case Alternate:
+		methodCall.SetCallee(r.options.Alternate)
+	case Stub:
+		proxyz.SetError(methodCall, r.options.StubError)
+		return
+	}
+
+	methodCall.Forward()
+}
+
+func getContext(methodCall proxyz.MethodCall) context.Context {
+	if ctx, ok := proxyz.GetContext(methodCall); ok {
+		return ctx
+	}
+
+	return context.Background()
+}
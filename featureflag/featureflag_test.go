@@ -0,0 +1,95 @@
+package featureflag_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/roy2220/proxyz"
+	"github.com/roy2220/proxyz/featureflag"
+	"github.com/roy2220/proxyz/internal/testproxy"
+)
+
+func TestRouter(t *testing.T) {
+	underlying := testproxy.MapKV{"foo": "underlying"}
+	alternate := testproxy.MapKV{"foo": "alternate"}
+	provider := new(featureflag.StaticProvider).Init(map[string]bool{"new-kv": true})
+	kv := testproxy.NewKVProxy(underlying)
+	router := new(featureflag.Router).Init(kv, featureflag.ContextProvider(provider), featureflag.Options{
+		FlagName:        "new-kv",
+		MethodFlagNames: map[string]string{"Set": "kv-writable"},
+		OnTarget:        featureflag.Alternate,
+		OffTarget:       featureflag.Underlying,
+		Alternate:       alternate,
+	})
+	proxyz.InterceptAllMethodCalls(kv, router.InterceptMethodCall)
+	ctx := context.Background()
+
+	v, _ := kv.Get(ctx, "foo")
+	assert.Equal(t, "alternate", v)
+	assert.Equal(t, []string{"foo"}, kv.Keys())
+	v, _ = kv.Get(featureflag.WithFlag(ctx, "new-kv", false), "foo")
+	assert.Equal(t, "underlying", v)
+
+	assert.NoError(t, kv.Set(ctx, "bar", "baz"))
+	assert.Equal(t, "baz", underlying["bar"])
+	assert.NoError(t, kv.Set(featureflag.WithFlag(ctx, "kv-writable", true), "qux", "baz"))
+	assert.Equal(t, "baz", alternate["qux"])
+
+	provider.Set("new-kv", false)
+	v, _ = kv.Get(ctx, "foo")
+	assert.Equal(t, "underlying", v)
+	ctx2 := featureflag.WithFlag(featureflag.WithFlag(ctx, "new-kv", true), "kv-writable", false)
+	v, _ = kv.Get(ctx2, "foo")
+	assert.Equal(t, "alternate", v)
+}
+
+func TestRouterStub(t *testing.T) {
+	provider := new(featureflag.StaticProvider)
+	kv := testproxy.NewKVProxy(testproxy.MapKV{"foo": "bar"})
+	router := new(featureflag.Router).Init(kv, provider, featureflag.Options{
+		FlagName: "kill-switch",
+		OnTarget: featureflag.Stub,
+	})
+	proxyz.InterceptAllMethodCalls(kv, router.InterceptMethodCall)
+	ctx := context.Background()
+
+	v, err := kv.Get(ctx, "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", v)
+
+	provider.Set("kill-switch", true)
+	v, err = kv.Get(ctx, "foo")
+	assert.Equal(t, featureflag.ErrStubbed, err)
+	assert.Equal(t, "", v)
+	assert.Nil(t, kv.Keys())
+	assert.Equal(t, "stub", featureflag.Stub.String())
+
+	assert.Panics(t, func() {
+		new(featureflag.Router).Init(kv, provider, featureflag.Options{OnTarget: featureflag.Alternate})
+	})
+	assert.Panics(t, func() {
+		new(featureflag.Router).Init(kv, provider, featureflag.Options{
+			OnTarget:  featureflag.Alternate,
+			Alternate: testproxy.AliasingSorter{},
+		})
+	})
+}
+
+func TestGate(t *testing.T) {
+	provider := featureflag.ContextProvider(nil)
+	kv := testproxy.NewKVProxy(testproxy.MapKV{})
+	var methodNames []string
+	proxyz.InterceptAllMethodCalls(kv, featureflag.Gate(provider, "debug", func(mc proxyz.MethodCall) {
+		methodNames = append(methodNames, mc.MethodName())
+		mc.Forward()
+	}))
+	ctx := context.Background()
+
+	kv.Set(ctx, "foo", "bar")
+	kv.Keys()
+	assert.Empty(t, methodNames)
+	kv.Get(featureflag.WithFlag(ctx, "debug", true), "foo")
+	assert.Equal(t, []string{"Get"}, methodNames)
+}
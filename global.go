@@ -0,0 +1,179 @@
+package proxyz
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// GlobalInterceptorFilter is the type of function deciding whether a global
+// interceptor should be applied to the calls to the method with the given
+// name, of the proxies with the given underlying type (see
+// Proxy.XxxUnderlyingType).
+type GlobalInterceptorFilter func(underlyingType string, methodName string) bool
+
+// InterceptAll adds a global interceptor to intercept the calls to all the
+// methods of all the proxies, including the ones created before. It is
+// intended to be called at program start, as the interceptor, being unnamed,
+// can not be removed.
+func InterceptAll(methodCallInterceptor MethodCallInterceptor) {
+	AddGlobalInterceptor(nil, methodCallInterceptor, InterceptorOptions{})
+}
+
+// InterceptAllOfType adds a global interceptor to intercept the calls to all
+// the methods of all the proxies with the given underlying type (see
+// Proxy.XxxUnderlyingType). See InterceptAll.
+func InterceptAllOfType(underlyingType string, methodCallInterceptor MethodCallInterceptor) {
+	AddGlobalInterceptor(func(underlyingType2 string, _ string) bool {
+		return underlyingType2 == underlyingType
+	}, methodCallInterceptor, InterceptorOptions{})
+}
+
+// AddGlobalInterceptor adds a global interceptor, with the given filter and
+// options, to intercept the calls to the methods of all the proxies.
+// A nil filter matches all the methods.
+//
+// Global interceptors are called before the interceptors added to the
+// proxies, and are ordered among themselves by the options.
+func AddGlobalInterceptor(filter GlobalInterceptorFilter, methodCallInterceptor MethodCallInterceptor, options InterceptorOptions) {
+	globalInterceptorsMutex.Lock()
+	defer globalInterceptorsMutex.Unlock()
+	allGlobalInterceptors.AddFilteredItem(0, methodCallInterceptor, filter, options)
+	storeGlobalInterceptors()
+}
+
+// RemoveGlobalInterceptor removes the global interceptor with the given name.
+// It returns false if no such interceptor is found.
+func RemoveGlobalInterceptor(name string) bool {
+	globalInterceptorsMutex.Lock()
+	defer globalInterceptorsMutex.Unlock()
+
+	if !allGlobalInterceptors.RemoveItem(0, name) {
+		return false
+	}
+
+	storeGlobalInterceptors()
+	return true
+}
+
+// ListGlobalInterceptors returns the names of the global interceptors, in the
+// order they are called. Unnamed interceptors have empty names.
+func ListGlobalInterceptors() []string {
+	globalInterceptorsMutex.Lock()
+	defer globalInterceptorsMutex.Unlock()
+	return allGlobalInterceptors.GetItemNames(0)
+}
+
+type globalInterceptors struct {
+	Version int
+	Items   []MethodCallInterceptor
+	Filters []GlobalInterceptorFilter
+}
+
+var (
+	globalInterceptorsMutex   sync.Mutex
+	allGlobalInterceptors     methodCallInterceptors
+	currentGlobalInterceptors atomic.Value
+)
+
+func storeGlobalInterceptors() {
+	entries := allGlobalInterceptors.GetEntries(0)
+
+	gi := globalInterceptors{
+		Version: allGlobalInterceptors.Version(),
+		Items:   make([]MethodCallInterceptor, len(entries)),
+		Filters: make([]GlobalInterceptorFilter, len(entries)),
+	}
+
+	for i := range entries {
+		gi.Items[i] = entries[i].InstrumentedItem()
+		gi.Filters[i] = entries[i].Filter
+	}
+
+	currentGlobalInterceptors.Store(&gi)
+}
+
+func loadGlobalInterceptors() *globalInterceptors {
+	gi, _ := currentGlobalInterceptors.Load().(*globalInterceptors)
+
+	if gi == nil || len(gi.Items) == 0 {
+		return nil
+	}
+
+	return gi
+}
+
+type resolvedInterceptors struct {
+	mutex    sync.Mutex
+	snapshot atomic.Value
+}
+
+type resolvedInterceptorsSnapshot struct {
+	GlobalVersion     int
+	LocalVersion      int
+	EventBus          *EventBus
+	MethodIndex2Items map[int][]MethodCallInterceptor
+}
+
+func (ri *resolvedInterceptors) GetItems(
+	proxy Proxy,
+	methodIndex int,
+	globalInterceptors *globalInterceptors,
+	localInterceptors *localInterceptors,
+	eventBus *EventBus,
+) []MethodCallInterceptor {
+	globalVersion := -1
+
+	if globalInterceptors != nil {
+		globalVersion = globalInterceptors.Version
+	}
+
+	snapshot, _ := ri.snapshot.Load().(*resolvedInterceptorsSnapshot)
+
+	if snapshot != nil && snapshot.GlobalVersion == globalVersion &&
+		snapshot.LocalVersion == localInterceptors.GetVersion() && snapshot.EventBus == eventBus {
+		if items, ok := snapshot.MethodIndex2Items[methodIndex]; ok {
+			return items
+		}
+	}
+
+	ri.mutex.Lock()
+	defer ri.mutex.Unlock()
+	snapshot, _ = ri.snapshot.Load().(*resolvedInterceptorsSnapshot)
+	newSnapshot := resolvedInterceptorsSnapshot{
+		GlobalVersion:     globalVersion,
+		LocalVersion:      localInterceptors.GetVersion(),
+		EventBus:          eventBus,
+		MethodIndex2Items: make(map[int][]MethodCallInterceptor),
+	}
+
+	if snapshot != nil && snapshot.GlobalVersion == newSnapshot.GlobalVersion &&
+		snapshot.LocalVersion == newSnapshot.LocalVersion && snapshot.EventBus == newSnapshot.EventBus {
+		if items, ok := snapshot.MethodIndex2Items[methodIndex]; ok {
+			return items
+		}
+
+		for methodIndex, items := range snapshot.MethodIndex2Items {
+			newSnapshot.MethodIndex2Items[methodIndex] = items
+		}
+	}
+
+	underlyingType, methodName := proxy.XxxUnderlyingType(), proxy.XxxGetMethodName(methodIndex)
+	var items []MethodCallInterceptor
+
+	if eventBus != nil {
+		items = append(items, eventBus.newMethodCallInterceptor(underlyingType))
+	}
+
+	if globalInterceptors != nil {
+		for i, item := range globalInterceptors.Items {
+			if filter := globalInterceptors.Filters[i]; filter == nil || filter(underlyingType, methodName) {
+				items = append(items, item)
+			}
+		}
+	}
+
+	items = append(items, localInterceptors.GetItems(methodIndex)...)
+	newSnapshot.MethodIndex2Items[methodIndex] = items
+	ri.snapshot.Store(&newSnapshot)
+	return items
+}
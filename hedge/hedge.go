@@ -0,0 +1,188 @@
+// Package hedge provides an interceptor issuing hedged requests to cut the
+// tail latency of idempotent methods.
+package hedge
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/roy2220/proxyz"
+)
+
+// Options represents options for hedgers.
+type Options struct {
+	// Delay is how long to wait for the outstanding attempts before issuing
+	// another attempt.
+	Delay time.Duration
+
+	// MaxAttempts is the maximum number of the attempts of a method call,
+	// including the first one. If not specified, 2 will be used.
+	MaxAttempts int
+}
+
+// Stats represents the stats of hedgers.
+type Stats struct {
+	// Calls is the number of the method calls intercepted.
+	Calls uint64
+
+	// HedgedAttempts is the number of the attempts issued after the first
+	// ones.
+	HedgedAttempts uint64
+
+	// HedgeWins is the number of the method calls won by the attempts issued
+	// after the first ones.
+	HedgeWins uint64
+}
+
+// Hedger issues another attempt of a method call, to the underlying object, if
+// the outstanding attempts haven't returned after a delay. The first
+// successful attempt, which doesn't panic or fail with the trailing error
+// result, wins. If all the attempts fail, the first failed one wins. The losers
+// are cancelled through their context.Context arguments, if any, and their
+// results are discarded.
+//
+// The context.Context argument of the winner is cancelled as well once the
+// results are copied back, so the methods returning results bound to the
+// context, e.g. streams or iterators, should not be hedged.
+//
+// It should only intercept the calls to idempotent methods (see
+// proxyz.OnlyMethods), and the underlying object should be safe for concurrent
+// use. The attempts forward the independent copies of the method call (see
+// proxyz.MethodCall.Clone), so the interceptors after the hedger are called
+// once per attempt.
+type Hedger struct {
+	options Options
+	stats   Stats
+}
+
+// Init initializes the hedger with the given options and returns it.
+func (h *Hedger) Init(options Options) *Hedger {
+	if options.Delay <= 0 {
+		panic(errors.New("hedge: non-positive delay"))
+	}
+
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = 2
+	}
+
+	h.options = options
+	return h
+}
+
+// Stats returns the stats of the hedger.
+func (h *Hedger) Stats() Stats {
+	return Stats{
+		Calls:          atomic.LoadUint64(&h.stats.Calls),
+		HedgedAttempts: atomic.LoadUint64(&h.stats.HedgedAttempts),
+		HedgeWins:      atomic.LoadUint64(&h.stats.HedgeWins),
+	}
+}
+
+// InterceptMethodCall is a proxyz.MethodCallInterceptor issuing hedged
+// attempts of the method call.
+func (h *Hedger) InterceptMethodCall(methodCall proxyz.MethodCall) {
+	atomic.AddUint64(&h.stats.Calls, 1)
+	completedAttempts := make(chan *attempt, h.options.MaxAttempts)
+	attempts := make([]*attempt, 0, h.options.MaxAttempts)
+
+	defer func() {
+		for _, attempt := range attempts {
+			if attempt.Cancel != nil {
+				attempt.Cancel()
+			}
+		}
+	}()
+
+	startAttempt := func() {
+		attempt := &attempt{Index: len(attempts), MethodCall: methodCall.Clone()}
+
+		if ctx, ok := proxyz.GetContext(attempt.MethodCall); ok {
+			ctx, attempt.Cancel = context.WithCancel(ctx)
+			attempt.MethodCall.SetArg(methodCall.ContextArgIndex(), ctx)
+		}
+
+		attempts = append(attempts, attempt)
+		go attempt.Run(completedAttempts)
+	}
+
+	startAttempt()
+	timer := time.NewTimer(h.options.Delay)
+	defer timer.Stop()
+	numberOfPendingAttempts := 1
+	var winner, firstFailedAttempt *attempt
+
+	for winner == nil {
+		select {
+		case attempt := <-completedAttempts:
+			numberOfPendingAttempts--
+
+			if attempt.IsSuccessful() {
+				winner = attempt
+				break
+			}
+
+			if firstFailedAttempt == nil {
+				firstFailedAttempt = attempt
+			}
+
+			if numberOfPendingAttempts == 0 {
+				winner = firstFailedAttempt
+			}
+		case <-timer.C:
+			if len(attempts) == h.options.MaxAttempts {
+				break
+			}
+
+			startAttempt()
+			numberOfPendingAttempts++
+			atomic.AddUint64(&h.stats.HedgedAttempts, 1)
+
+			if len(attempts) < h.options.MaxAttempts {
+				timer.Reset(h.options.Delay)
+			}
+		}
+	}
+
+	if winner.Index >= 1 {
+		atomic.AddUint64(&h.stats.HedgeWins, 1)
+	}
+
+	switch winner.Outcome {
+	case proxyz.Panicked:
+		panic(winner.PanicValue)
+	case proxyz.Goexited:
+		runtime.Goexit()
+	}
+
+	for i, n := 0, methodCall.NumberOfResults(); i < n; i++ {
+		methodCall.SetResult(i, winner.MethodCall.GetResult(i))
+	}
+}
+
+type attempt struct {
+	Index      int
+	MethodCall proxyz.MethodCall
+	Cancel     context.CancelFunc
+	Outcome    proxyz.Outcome
+	PanicValue interface{}
+}
+
+func (a *attempt) Run(completedAttempts chan<- *attempt) {
+	defer func() {
+		// recover the panic re-raised by proxyz.ForwardAndObserve
+		recover()
+		completedAttempts <- a
+	}()
+
+	proxyz.ForwardAndObserve(a.MethodCall, func(outcome proxyz.Outcome, panicValue interface{}) {
+		a.Outcome = outcome
+		a.PanicValue = panicValue
+	})
+}
+
+func (a *attempt) IsSuccessful() bool {
+	return a.Outcome == proxyz.Returned && proxyz.GetError(a.MethodCall) == nil
+}
@@ -0,0 +1,130 @@
+package hedge_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/roy2220/proxyz"
+	"github.com/roy2220/proxyz/hedge"
+	"github.com/roy2220/proxyz/internal/testproxy"
+)
+
+type slowKV struct {
+	testproxy.MapKV
+
+	attemptCount int32
+	cancelled    chan struct{}
+	getHook      func(attemptIndex int32) error
+}
+
+func (skv *slowKV) Get(ctx context.Context, key string) (string, error) {
+	attemptIndex := atomic.AddInt32(&skv.attemptCount, 1) - 1
+
+	if err := skv.getHook(attemptIndex); err != nil {
+		return "", err
+	}
+
+	if attemptIndex == 0 {
+		select {
+		case <-ctx.Done():
+			close(skv.cancelled)
+			return "", ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+
+	return skv.MapKV.Get(ctx, key)
+}
+
+func TestHedger(t *testing.T) {
+	underlying := &slowKV{
+		MapKV:     testproxy.MapKV{"foo": "bar"},
+		cancelled: make(chan struct{}),
+		getHook:   func(int32) error { return nil },
+	}
+	hedger := new(hedge.Hedger).Init(hedge.Options{Delay: 20 * time.Millisecond})
+	kv := testproxy.NewKVProxy(underlying)
+	kv.XxxInterceptMethodCall(testproxy.KVProxyGet, hedger.InterceptMethodCall)
+	var attemptCount int32
+	kv.XxxInterceptMethodCall(testproxy.KVProxyGet, func(mc proxyz.MethodCall) {
+		atomic.AddInt32(&attemptCount, 1)
+		mc.Forward()
+	})
+
+	startTime := time.Now()
+	v, err := kv.Get(context.Background(), "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", v)
+	assert.True(t, time.Since(startTime) < 500*time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attemptCount))
+
+	select {
+	case <-underlying.cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("loser not cancelled")
+	}
+
+	assert.Equal(t, hedge.Stats{Calls: 1, HedgedAttempts: 1, HedgeWins: 1}, hedger.Stats())
+
+	atomic.StoreInt32(&underlying.attemptCount, 1)
+	v, err = kv.Get(context.Background(), "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", v)
+	assert.Equal(t, hedge.Stats{Calls: 2, HedgedAttempts: 1, HedgeWins: 1}, hedger.Stats())
+}
+
+func TestHedgerFailure(t *testing.T) {
+	errFirst, errSecond := errors.New("first"), errors.New("second")
+	underlying := &slowKV{
+		MapKV:     testproxy.MapKV{"foo": "bar"},
+		cancelled: make(chan struct{}),
+		getHook: func(attemptIndex int32) error {
+			time.Sleep(time.Duration(50*(1-attemptIndex)) * time.Millisecond)
+
+			if attemptIndex == 0 {
+				return errFirst
+			}
+
+			return errSecond
+		},
+	}
+	hedger := new(hedge.Hedger).Init(hedge.Options{Delay: 10 * time.Millisecond, MaxAttempts: 3})
+	kv := testproxy.NewKVProxy(underlying)
+	kv.XxxInterceptMethodCall(testproxy.KVProxyGet, hedger.InterceptMethodCall)
+
+	_, err := kv.Get(context.Background(), "foo")
+	assert.Equal(t, errSecond, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&underlying.attemptCount))
+	assert.Equal(t, hedge.Stats{Calls: 1, HedgedAttempts: 2, HedgeWins: 1}, hedger.Stats())
+
+	kv.XxxInterceptMethodCall(testproxy.KVProxyGet, func(mc proxyz.MethodCall) {
+		panic("boom")
+	})
+	assert.PanicsWithValue(t, "boom", func() { kv.Get(context.Background(), "foo") })
+	assert.Panics(t, func() { new(hedge.Hedger).Init(hedge.Options{}) })
+}
+
+func TestHedgerMaxAttempts(t *testing.T) {
+	underlying := &slowKV{
+		MapKV:     testproxy.MapKV{"foo": "bar"},
+		cancelled: make(chan struct{}),
+		getHook: func(int32) error {
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		},
+	}
+	hedger := new(hedge.Hedger).Init(hedge.Options{Delay: 10 * time.Millisecond, MaxAttempts: 1})
+	kv := testproxy.NewKVProxy(underlying)
+	kv.XxxInterceptMethodCall(testproxy.KVProxyGet, hedger.InterceptMethodCall)
+
+	v, err := kv.Get(context.Background(), "bar")
+	assert.Equal(t, testproxy.ErrNotFound, err)
+	assert.Equal(t, "", v)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&underlying.attemptCount))
+	assert.Equal(t, hedge.Stats{Calls: 1}, hedger.Stats())
+}
@@ -0,0 +1,314 @@
+package proxyz
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// InterceptorOptions represents options for interceptors.
+//
+// Interceptors are ordered by the priorities first, then the constraints
+// Before and After are applied, interceptors added earlier are called earlier
+// when there is a tie.
+type InterceptorOptions struct {
+	// Name is the name of the interceptor, which should be unique among the
+	// interceptors applied to the calls to the same method. Unnamed
+	// interceptors can not be replaced, removed or referred to.
+	Name string
+
+	// Priority is the priority of the interceptor. Interceptors with higher
+	// priorities are called earlier, that is, they wrap the interceptors
+	// with lower priorities.
+	Priority int
+
+	// Before lists the names of the interceptors which the interceptor
+	// should be called before.
+	Before []string
+
+	// After lists the names of the interceptors which the interceptor should
+	// be called after.
+	After []string
+}
+
+type methodCallInterceptors struct {
+	methodIndex2Entries map[int]*interceptorEntries
+	nextSequenceNumber  int
+	version             int
+}
+
+func (mci *methodCallInterceptors) AddItem(methodIndex int, item MethodCallInterceptor, options InterceptorOptions) {
+	mci.AddFilteredItem(methodIndex, item, nil, options)
+}
+
+func (mci *methodCallInterceptors) AddFilteredItem(
+	methodIndex int,
+	item MethodCallInterceptor,
+	filter GlobalInterceptorFilter,
+	options InterceptorOptions,
+) {
+	if mci.methodIndex2Entries == nil {
+		mci.methodIndex2Entries = make(map[int]*interceptorEntries)
+	}
+
+	entries, ok := mci.methodIndex2Entries[methodIndex]
+
+	if !ok {
+		entries = new(interceptorEntries)
+		mci.methodIndex2Entries[methodIndex] = entries
+	}
+
+	if options.Name != "" && entries.Find(options.Name) >= 0 {
+		panic(fmt.Sprintf("proxyz: duplicate interceptor name; methodIndex=%d name=%q", methodIndex, options.Name))
+	}
+
+	sequenceNumber := mci.nextSequenceNumber
+	mci.nextSequenceNumber++
+	mci.version++
+	entries.List = append(entries.List, interceptorEntry{
+		Item:           item,
+		Filter:         filter,
+		Options:        options,
+		SequenceNumber: sequenceNumber,
+		Stats:          new(interceptorStats),
+	})
+
+	if !entries.Sort() {
+		for i := range entries.List {
+			if entries.List[i].SequenceNumber == sequenceNumber {
+				entries.List = append(entries.List[:i:i], entries.List[i+1:]...)
+				break
+			}
+		}
+
+		entries.Sort()
+		panic(fmt.Sprintf("proxyz: cyclic interceptor order constraints; methodIndex=%d name=%q", methodIndex, options.Name))
+	}
+}
+
+func (mci *methodCallInterceptors) ReplaceItem(methodIndex int, name string, item MethodCallInterceptor) bool {
+	entries, ok := mci.methodIndex2Entries[methodIndex]
+
+	if !ok {
+		return false
+	}
+
+	i := entries.Find(name)
+
+	if i < 0 {
+		return false
+	}
+
+	entries.List[i].Item = item
+	entries.List[i].Stats = new(interceptorStats)
+	entries.Sort()
+	mci.version++
+	return true
+}
+
+func (mci *methodCallInterceptors) RemoveItem(methodIndex int, name string) bool {
+	entries, ok := mci.methodIndex2Entries[methodIndex]
+
+	if !ok {
+		return false
+	}
+
+	i := entries.Find(name)
+
+	if i < 0 {
+		return false
+	}
+
+	entries.List = append(entries.List[:i:i], entries.List[i+1:]...)
+	entries.Sort()
+	mci.version++
+	return true
+}
+
+func (mci *methodCallInterceptors) GetItems(methodIndex int) []MethodCallInterceptor {
+	if entries, ok := mci.methodIndex2Entries[methodIndex]; ok {
+		return entries.Items
+	}
+
+	return nil
+}
+
+func (mci *methodCallInterceptors) GetEntries(methodIndex int) []interceptorEntry {
+	if entries, ok := mci.methodIndex2Entries[methodIndex]; ok {
+		return entries.List
+	}
+
+	return nil
+}
+
+func (mci *methodCallInterceptors) Version() int {
+	return mci.version
+}
+
+func (mci *methodCallInterceptors) GetItemNames(methodIndex int) []string {
+	entries, ok := mci.methodIndex2Entries[methodIndex]
+
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, len(entries.List))
+
+	for i := range entries.List {
+		names[i] = entries.List[i].Options.Name
+	}
+
+	return names
+}
+
+type interceptorEntries struct {
+	List  []interceptorEntry
+	Items []MethodCallInterceptor
+}
+
+type interceptorEntry struct {
+	Item           MethodCallInterceptor
+	Filter         GlobalInterceptorFilter
+	Options        InterceptorOptions
+	SequenceNumber int
+	Stats          *interceptorStats
+}
+
+func (ie *interceptorEntries) Find(name string) int {
+	for i := range ie.List {
+		if ie.List[i].Options.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (ie *interceptorEntries) Sort() bool {
+	list := ie.List
+
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Options.Priority != list[j].Options.Priority {
+			return list[i].Options.Priority > list[j].Options.Priority
+		}
+
+		return list[i].SequenceNumber < list[j].SequenceNumber
+	})
+
+	n := len(list)
+	name2Index := make(map[string]int, n)
+
+	for i := range list {
+		if name := list[i].Options.Name; name != "" {
+			name2Index[name] = i
+		}
+	}
+
+	// successors[i] lists the entries which should be called after entry i.
+	successors := make([][]int, n)
+	numbersOfPredecessors := make([]int, n)
+
+	addEdge := func(i, j int) {
+		successors[i] = append(successors[i], j)
+		numbersOfPredecessors[j]++
+	}
+
+	for i := range list {
+		for _, name := range list[i].Options.Before {
+			if j, ok := name2Index[name]; ok {
+				addEdge(i, j)
+			}
+		}
+
+		for _, name := range list[i].Options.After {
+			if j, ok := name2Index[name]; ok {
+				addEdge(j, i)
+			}
+		}
+	}
+
+	sortedList := make([]interceptorEntry, 0, n)
+	isSorted := make([]bool, n)
+
+	for len(sortedList) < n {
+		i := 0
+
+		for ; i < n; i++ {
+			if !isSorted[i] && numbersOfPredecessors[i] == 0 {
+				break
+			}
+		}
+
+		if i == n {
+			return false
+		}
+
+		isSorted[i] = true
+		sortedList = append(sortedList, list[i])
+
+		for _, j := range successors[i] {
+			numbersOfPredecessors[j]--
+		}
+	}
+
+	items := make([]MethodCallInterceptor, n)
+
+	for i := range sortedList {
+		items[i] = sortedList[i].InstrumentedItem()
+	}
+
+	ie.List = sortedList
+	ie.Items = items
+	return true
+}
+
+// EnableInterceptorStats enables or disables the stats of interceptors, i.e.
+// the call counts and the accumulated time (see InterceptorInfo), which are
+// disabled by default as they add overhead to every method call intercepted.
+// It takes effect immediately for all the interceptors, including the ones
+// added before.
+func EnableInterceptorStats(isEnabled bool) {
+	var value int32
+
+	if isEnabled {
+		value = 1
+	}
+
+	atomic.StoreInt32(&interceptorStatsEnabled, value)
+}
+
+var interceptorStatsEnabled int32
+
+// InstrumentedItem returns the item wrapped to update the stats while the
+// stats are enabled.
+func (ie *interceptorEntry) InstrumentedItem() MethodCallInterceptor {
+	item, stats := ie.Item, ie.Stats
+
+	return func(methodCall MethodCall) {
+		if atomic.LoadInt32(&interceptorStatsEnabled) == 0 {
+			item(methodCall)
+			return
+		}
+
+		startTime := time.Now()
+		defer stats.Update(startTime)
+		item(methodCall)
+	}
+}
+
+type interceptorStats struct {
+	callCount       uint64
+	totalTimeInNano uint64
+}
+
+func (is *interceptorStats) Update(startTime time.Time) {
+	atomic.AddUint64(&is.callCount, 1)
+	atomic.AddUint64(&is.totalTimeInNano, uint64(time.Since(startTime)))
+}
+
+func (is *interceptorStats) CallCount() uint64 { return atomic.LoadUint64(&is.callCount) }
+
+func (is *interceptorStats) TotalTime() time.Duration {
+	return time.Duration(atomic.LoadUint64(&is.totalTimeInNano))
+}
@@ -0,0 +1,62 @@
+// Package goroutine provides utility for goroutines.
+package goroutine
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// CurrentID returns the id of the current goroutine.
+func CurrentID() int64 {
+	var buffer [64]byte
+	stack := buffer[:runtime.Stack(buffer[:], false)]
+	// goroutine 123 [running]:
+	stack = bytes.TrimPrefix(stack, []byte("goroutine "))
+
+	if i := bytes.IndexByte(stack, ' '); i >= 0 {
+		stack = stack[:i]
+	}
+
+	id, _ := strconv.ParseInt(string(stack), 10, 64)
+	return id
+}
+
+// CurrentStack returns the stack of the current goroutine.
+func CurrentStack() string {
+	return stack(false)
+}
+
+// AllStacks returns the stacks of all the goroutines.
+func AllStacks() string {
+	return stack(true)
+}
+
+// FindStack returns the stack of the goroutine with the given id from the
+// given stacks of all the goroutines, or "" if not found.
+func FindStack(allStacks string, id int64) string {
+	prefix := "goroutine " + strconv.FormatInt(id, 10) + " "
+
+	for _, stack := range strings.Split(allStacks, "\n\n") {
+		if strings.HasPrefix(stack, prefix) {
+			return stack
+		}
+	}
+
+	return ""
+}
+
+func stack(all bool) string {
+	buffer := make([]byte, 1<<16)
+
+	for {
+		n := runtime.Stack(buffer, all)
+
+		if n < len(buffer) {
+			return string(buffer[:n])
+		}
+
+		buffer = make([]byte, 2*len(buffer))
+	}
+}
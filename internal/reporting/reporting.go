@@ -0,0 +1,23 @@
+// Package reporting provides utility for the reports of interceptors.
+package reporting
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// WriteToStderr writes the given report to stderr. It serves as the default
+// reporter.
+func WriteToStderr(report io.WriterTo) {
+	report.WriteTo(os.Stderr)
+}
+
+// WriteArgs writes the given arguments formatted, one per line prefixed with
+// the given indent, to the given buffer.
+func WriteArgs(buffer *bytes.Buffer, indent string, args []string) {
+	for i, arg := range args {
+		fmt.Fprintf(buffer, "%sarg%d: %s\n", indent, i, arg)
+	}
+}
@@ -0,0 +1,457 @@
+// Code generated by proxyz. DO NOT EDIT.
+package testproxy
+
+import (
+	context "context"
+	proxyz "github.com/roy2220/proxyz"
+)
+
+type AccountStoreProxy struct {
+	proxyz.XxxProxyBase
+	AccountStore
+}
+
+var _ = (proxyz.Proxy)((*AccountStoreProxy)(nil))
+
+func NewAccountStoreProxy(underlying AccountStore) *AccountStoreProxy {
+	return &AccountStoreProxy{
+		AccountStore: underlying,
+	}
+}
+
+const AccountStoreProxyCountAccounts = 0
+
+type AccountStoreProxyCountAccountsCall struct {
+	proxyz.XxxMethodCallBase
+
+	Result0 int
+
+	callee               AccountStore
+	interceptors         []proxyz.MethodCallInterceptor
+	nextInterceptorIndex int
+}
+
+var _ = (proxyz.MethodCall)((*AccountStoreProxyCountAccountsCall)(nil))
+
+func (mc *AccountStoreProxyCountAccountsCall) Forward() {
+	if interceptor, ok := mc.getNextInterceptor(); ok {
+		interceptor(mc)
+		return
+	}
+
+	mc.Result0 = mc.callee.CountAccounts()
+}
+
+func (mc *AccountStoreProxyCountAccountsCall) GetArg(argIndex int) interface{} {
+	switch argIndex {
+	default:
+		panic("arg index out of range")
+	}
+}
+
+func (mc *AccountStoreProxyCountAccountsCall) SetArg(argIndex int, arg interface{}) {
+	switch argIndex {
+	default:
+		panic("arg index out of range")
+	}
+}
+
+func (mc *AccountStoreProxyCountAccountsCall) GetResult(resultIndex int) interface{} {
+	switch resultIndex {
+	case 0:
+		return mc.Result0
+	default:
+		panic("result index out of range")
+	}
+}
+
+func (mc *AccountStoreProxyCountAccountsCall) SetResult(resultIndex int, result interface{}) {
+	switch resultIndex {
+	case 0:
+		if result == nil {
+			mc.Result0 = *new(int)
+		} else {
+			mc.Result0 = result.(int)
+		}
+	default:
+		panic("result index out of range")
+	}
+}
+
+func (mc *AccountStoreProxyCountAccountsCall) UnderlyingType() string {
+	return "github.com/roy2220/proxyz/internal/testproxy.AccountStore"
+}
+func (mc *AccountStoreProxyCountAccountsCall) MethodName() string { return "CountAccounts" }
+func (mc *AccountStoreProxyCountAccountsCall) MethodIndex() int {
+	return AccountStoreProxyCountAccounts
+}
+func (mc *AccountStoreProxyCountAccountsCall) NumberOfArgs() int     { return 0 }
+func (mc *AccountStoreProxyCountAccountsCall) NumberOfResults() int  { return 1 }
+func (mc *AccountStoreProxyCountAccountsCall) ContextArgIndex() int  { return -1 }
+func (mc *AccountStoreProxyCountAccountsCall) ErrorResultIndex() int { return -1 }
+
+func (mc *AccountStoreProxyCountAccountsCall) ArgName(argIndex int) string {
+	return [...]string{}[argIndex]
+}
+
+func (mc *AccountStoreProxyCountAccountsCall) IsArgSensitive(argIndex int) bool {
+	return false
+}
+
+func (mc *AccountStoreProxyCountAccountsCall) String() string { return proxyz.FormatMethodCall(mc) }
+
+func (mc *AccountStoreProxyCountAccountsCall) Callee() interface{} { return mc.callee }
+func (mc *AccountStoreProxyCountAccountsCall) SetCallee(callee interface{}) {
+	mc.callee = callee.(AccountStore)
+}
+
+func (mc *AccountStoreProxyCountAccountsCall) Clone() proxyz.MethodCall {
+	clone := *mc
+	clone.XxxInitClone(&clone)
+	return &clone
+}
+
+func (mc *AccountStoreProxyCountAccountsCall) getNextInterceptor() (proxyz.MethodCallInterceptor, bool) {
+	if i := mc.nextInterceptorIndex; i < len(mc.interceptors) {
+		mc.nextInterceptorIndex++
+		return mc.interceptors[i], true
+	}
+
+	return nil, false
+}
+
+func (p *AccountStoreProxy) CountAccounts() int {
+	methodCallInterceptors := p.XxxResolveMethodCallInterceptors(p, AccountStoreProxyCountAccounts)
+
+	if len(methodCallInterceptors) == 0 {
+		return p.AccountStore.CountAccounts()
+	}
+
+	methodCall := AccountStoreProxyCountAccountsCall{
+		callee:       p.AccountStore,
+		interceptors: methodCallInterceptors,
+	}
+
+	methodCall.XxxInit(&methodCall)
+	methodCall.Forward()
+	return methodCall.Result0
+}
+
+const AccountStoreProxyCreateAccount = 1
+
+type AccountStoreProxyCreateAccountCall struct {
+	proxyz.XxxMethodCallBase
+
+	Arg0    context.Context
+	Arg1    *Account
+	Result0 error
+
+	callee               AccountStore
+	interceptors         []proxyz.MethodCallInterceptor
+	nextInterceptorIndex int
+}
+
+var _ = (proxyz.MethodCall)((*AccountStoreProxyCreateAccountCall)(nil))
+
+func (mc *AccountStoreProxyCreateAccountCall) Forward() {
+	if interceptor, ok := mc.getNextInterceptor(); ok {
+		interceptor(mc)
+		return
+	}
+
+	mc.Result0 = mc.callee.CreateAccount(mc.Arg0, mc.Arg1)
+}
+
+func (mc *AccountStoreProxyCreateAccountCall) GetArg(argIndex int) interface{} {
+	switch argIndex {
+	case 0:
+		return mc.Arg0
+	case 1:
+		return mc.Arg1
+	default:
+		panic("arg index out of range")
+	}
+}
+
+func (mc *AccountStoreProxyCreateAccountCall) SetArg(argIndex int, arg interface{}) {
+	switch argIndex {
+	case 0:
+		if arg == nil {
+			mc.Arg0 = *new(context.Context)
+		} else {
+			mc.Arg0 = arg.(context.Context)
+		}
+	case 1:
+		if arg == nil {
+			mc.Arg1 = *new(*Account)
+		} else {
+			mc.Arg1 = arg.(*Account)
+		}
+	default:
+		panic("arg index out of range")
+	}
+}
+
+func (mc *AccountStoreProxyCreateAccountCall) GetResult(resultIndex int) interface{} {
+	switch resultIndex {
+	case 0:
+		return mc.Result0
+	default:
+		panic("result index out of range")
+	}
+}
+
+func (mc *AccountStoreProxyCreateAccountCall) SetResult(resultIndex int, result interface{}) {
+	switch resultIndex {
+	case 0:
+		if result == nil {
+			mc.Result0 = *new(error)
+		} else {
+			mc.Result0 = result.(error)
+		}
+	default:
+		panic("result index out of range")
+	}
+}
+
+func (mc *AccountStoreProxyCreateAccountCall) UnderlyingType() string {
+	return "github.com/roy2220/proxyz/internal/testproxy.AccountStore"
+}
+func (mc *AccountStoreProxyCreateAccountCall) MethodName() string { return "CreateAccount" }
+func (mc *AccountStoreProxyCreateAccountCall) MethodIndex() int {
+	return AccountStoreProxyCreateAccount
+}
+func (mc *AccountStoreProxyCreateAccountCall) NumberOfArgs() int     { return 2 }
+func (mc *AccountStoreProxyCreateAccountCall) NumberOfResults() int  { return 1 }
+func (mc *AccountStoreProxyCreateAccountCall) ContextArgIndex() int  { return 0 }
+func (mc *AccountStoreProxyCreateAccountCall) ErrorResultIndex() int { return 0 }
+
+func (mc *AccountStoreProxyCreateAccountCall) ArgName(argIndex int) string {
+	return [...]string{"ctx", "account"}[argIndex]
+}
+
+func (mc *AccountStoreProxyCreateAccountCall) IsArgSensitive(argIndex int) bool {
+	return false
+}
+
+func (mc *AccountStoreProxyCreateAccountCall) String() string { return proxyz.FormatMethodCall(mc) }
+
+func (mc *AccountStoreProxyCreateAccountCall) Callee() interface{} { return mc.callee }
+func (mc *AccountStoreProxyCreateAccountCall) SetCallee(callee interface{}) {
+	mc.callee = callee.(AccountStore)
+}
+
+func (mc *AccountStoreProxyCreateAccountCall) Clone() proxyz.MethodCall {
+	clone := *mc
+	clone.XxxInitClone(&clone)
+	return &clone
+}
+
+func (mc *AccountStoreProxyCreateAccountCall) getNextInterceptor() (proxyz.MethodCallInterceptor, bool) {
+	if i := mc.nextInterceptorIndex; i < len(mc.interceptors) {
+		mc.nextInterceptorIndex++
+		return mc.interceptors[i], true
+	}
+
+	return nil, false
+}
+
+func (p *AccountStoreProxy) CreateAccount(_ctx_ context.Context, _account_ *Account) error {
+	methodCallInterceptors := p.XxxResolveContextMethodCallInterceptors(_ctx_, p, AccountStoreProxyCreateAccount)
+
+	if len(methodCallInterceptors) == 0 {
+		return p.AccountStore.CreateAccount(_ctx_, _account_)
+	}
+
+	methodCall := AccountStoreProxyCreateAccountCall{
+		Arg0: _ctx_,
+		Arg1: _account_,
+
+		callee:       p.AccountStore,
+		interceptors: methodCallInterceptors,
+	}
+
+	methodCall.XxxInit(&methodCall)
+	methodCall.Forward()
+	return methodCall.Result0
+}
+
+const AccountStoreProxyLogin = 2
+
+type AccountStoreProxyLoginCall struct {
+	proxyz.XxxMethodCallBase
+
+	Arg0    context.Context
+	Arg1    string
+	Arg2    string
+	Arg3    string
+	Result0 error
+
+	callee               AccountStore
+	interceptors         []proxyz.MethodCallInterceptor
+	nextInterceptorIndex int
+}
+
+var _ = (proxyz.MethodCall)((*AccountStoreProxyLoginCall)(nil))
+
+func (mc *AccountStoreProxyLoginCall) Forward() {
+	if interceptor, ok := mc.getNextInterceptor(); ok {
+		interceptor(mc)
+		return
+	}
+
+	mc.Result0 = mc.callee.Login(mc.Arg0, mc.Arg1, mc.Arg2, mc.Arg3)
+}
+
+func (mc *AccountStoreProxyLoginCall) GetArg(argIndex int) interface{} {
+	switch argIndex {
+	case 0:
+		return mc.Arg0
+	case 1:
+		return mc.Arg1
+	case 2:
+		return mc.Arg2
+	case 3:
+		return mc.Arg3
+	default:
+		panic("arg index out of range")
+	}
+}
+
+func (mc *AccountStoreProxyLoginCall) SetArg(argIndex int, arg interface{}) {
+	switch argIndex {
+	case 0:
+		if arg == nil {
+			mc.Arg0 = *new(context.Context)
+		} else {
+			mc.Arg0 = arg.(context.Context)
+		}
+	case 1:
+		if arg == nil {
+			mc.Arg1 = *new(string)
+		} else {
+			mc.Arg1 = arg.(string)
+		}
+	case 2:
+		if arg == nil {
+			mc.Arg2 = *new(string)
+		} else {
+			mc.Arg2 = arg.(string)
+		}
+	case 3:
+		if arg == nil {
+			mc.Arg3 = *new(string)
+		} else {
+			mc.Arg3 = arg.(string)
+		}
+	default:
+		panic("arg index out of range")
+	}
+}
+
+func (mc *AccountStoreProxyLoginCall) GetResult(resultIndex int) interface{} {
+	switch resultIndex {
+	case 0:
+		return mc.Result0
+	default:
+		panic("result index out of range")
+	}
+}
+
+func (mc *AccountStoreProxyLoginCall) SetResult(resultIndex int, result interface{}) {
+	switch resultIndex {
+	case 0:
+		if result == nil {
+			mc.Result0 = *new(error)
+		} else {
+			mc.Result0 = result.(error)
+		}
+	default:
+		panic("result index out of range")
+	}
+}
+
+func (mc *AccountStoreProxyLoginCall) UnderlyingType() string {
+	return "github.com/roy2220/proxyz/internal/testproxy.AccountStore"
+}
+func (mc *AccountStoreProxyLoginCall) MethodName() string    { return "Login" }
+func (mc *AccountStoreProxyLoginCall) MethodIndex() int      { return AccountStoreProxyLogin }
+func (mc *AccountStoreProxyLoginCall) NumberOfArgs() int     { return 4 }
+func (mc *AccountStoreProxyLoginCall) NumberOfResults() int  { return 1 }
+func (mc *AccountStoreProxyLoginCall) ContextArgIndex() int  { return 0 }
+func (mc *AccountStoreProxyLoginCall) ErrorResultIndex() int { return 0 }
+
+func (mc *AccountStoreProxyLoginCall) ArgName(argIndex int) string {
+	return [...]string{"ctx", "name", "password", "code"}[argIndex]
+}
+
+func (mc *AccountStoreProxyLoginCall) IsArgSensitive(argIndex int) bool {
+	switch argIndex {
+	case 2, 3:
+		return true
+	default:
+		return false
+	}
+}
+
+func (mc *AccountStoreProxyLoginCall) String() string { return proxyz.FormatMethodCall(mc) }
+
+func (mc *AccountStoreProxyLoginCall) Callee() interface{} { return mc.callee }
+func (mc *AccountStoreProxyLoginCall) SetCallee(callee interface{}) {
+	mc.callee = callee.(AccountStore)
+}
+
+func (mc *AccountStoreProxyLoginCall) Clone() proxyz.MethodCall {
+	clone := *mc
+	clone.XxxInitClone(&clone)
+	return &clone
+}
+
+func (mc *AccountStoreProxyLoginCall) getNextInterceptor() (proxyz.MethodCallInterceptor, bool) {
+	if i := mc.nextInterceptorIndex; i < len(mc.interceptors) {
+		mc.nextInterceptorIndex++
+		return mc.interceptors[i], true
+	}
+
+	return nil, false
+}
+
+func (p *AccountStoreProxy) Login(_ctx_ context.Context, _name_ string, _password_ string, _code_ string) error {
+	methodCallInterceptors := p.XxxResolveContextMethodCallInterceptors(_ctx_, p, AccountStoreProxyLogin)
+
+	if len(methodCallInterceptors) == 0 {
+		return p.AccountStore.Login(_ctx_, _name_, _password_, _code_)
+	}
+
+	methodCall := AccountStoreProxyLoginCall{
+		Arg0: _ctx_,
+		Arg1: _name_,
+		Arg2: _password_,
+		Arg3: _code_,
+
+		callee:       p.AccountStore,
+		interceptors: methodCallInterceptors,
+	}
+
+	methodCall.XxxInit(&methodCall)
+	methodCall.Forward()
+	return methodCall.Result0
+}
+
+func (p *AccountStoreProxy) XxxGetMethodName(methodIndex int) string {
+	return [...]string{
+		AccountStoreProxyCountAccounts: "CountAccounts",
+		AccountStoreProxyCreateAccount: "CreateAccount",
+		AccountStoreProxyLogin:         "Login",
+	}[methodIndex]
+}
+
+func (p *AccountStoreProxy) XxxNumberOfMethods() int { return 3 }
+func (p *AccountStoreProxy) XxxUnderlyingType() string {
+	return "github.com/roy2220/proxyz/internal/testproxy.AccountStore"
+}
+
+func (p *AccountStoreProxy) XxxIsOfUnderlyingType(value interface{}) bool {
+	_, ok := value.(AccountStore)
+	return ok
+}
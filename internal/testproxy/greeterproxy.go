@@ -0,0 +1,180 @@
+// Code generated by proxyz. DO NOT EDIT.
+package testproxy
+
+import (
+	context "context"
+	proxyz "github.com/roy2220/proxyz"
+)
+
+type GreeterProxy struct {
+	proxyz.XxxProxyBase
+	Greeter
+}
+
+var _ = (proxyz.Proxy)((*GreeterProxy)(nil))
+
+func NewGreeterProxy(underlying Greeter) *GreeterProxy {
+	return &GreeterProxy{
+		Greeter: underlying,
+	}
+}
+
+const GreeterProxyGreet = 0
+
+type GreeterProxyGreetCall struct {
+	proxyz.XxxMethodCallBase
+
+	Arg0    context.Context
+	Arg1    string
+	Result0 string
+	Result1 error
+
+	callee               Greeter
+	interceptors         []proxyz.MethodCallInterceptor
+	nextInterceptorIndex int
+}
+
+var _ = (proxyz.MethodCall)((*GreeterProxyGreetCall)(nil))
+
+func (mc *GreeterProxyGreetCall) Forward() {
+	if interceptor, ok := mc.getNextInterceptor(); ok {
+		interceptor(mc)
+		return
+	}
+
+	mc.Result0, mc.Result1 = mc.callee.Greet(mc.Arg0, mc.Arg1)
+}
+
+func (mc *GreeterProxyGreetCall) GetArg(argIndex int) interface{} {
+	switch argIndex {
+	case 0:
+		return mc.Arg0
+	case 1:
+		return mc.Arg1
+	default:
+		panic("arg index out of range")
+	}
+}
+
+func (mc *GreeterProxyGreetCall) SetArg(argIndex int, arg interface{}) {
+	switch argIndex {
+	case 0:
+		if arg == nil {
+			mc.Arg0 = *new(context.Context)
+		} else {
+			mc.Arg0 = arg.(context.Context)
+		}
+	case 1:
+		if arg == nil {
+			mc.Arg1 = *new(string)
+		} else {
+			mc.Arg1 = arg.(string)
+		}
+	default:
+		panic("arg index out of range")
+	}
+}
+
+func (mc *GreeterProxyGreetCall) GetResult(resultIndex int) interface{} {
+	switch resultIndex {
+	case 0:
+		return mc.Result0
+	case 1:
+		return mc.Result1
+	default:
+		panic("result index out of range")
+	}
+}
+
+func (mc *GreeterProxyGreetCall) SetResult(resultIndex int, result interface{}) {
+	switch resultIndex {
+	case 0:
+		if result == nil {
+			mc.Result0 = *new(string)
+		} else {
+			mc.Result0 = result.(string)
+		}
+	case 1:
+		if result == nil {
+			mc.Result1 = *new(error)
+		} else {
+			mc.Result1 = result.(error)
+		}
+	default:
+		panic("result index out of range")
+	}
+}
+
+func (mc *GreeterProxyGreetCall) UnderlyingType() string {
+	return "github.com/roy2220/proxyz/internal/testproxy.Greeter"
+}
+func (mc *GreeterProxyGreetCall) MethodName() string    { return "Greet" }
+func (mc *GreeterProxyGreetCall) MethodIndex() int      { return GreeterProxyGreet }
+func (mc *GreeterProxyGreetCall) NumberOfArgs() int     { return 2 }
+func (mc *GreeterProxyGreetCall) NumberOfResults() int  { return 2 }
+func (mc *GreeterProxyGreetCall) ContextArgIndex() int  { return 0 }
+func (mc *GreeterProxyGreetCall) ErrorResultIndex() int { return 1 }
+
+func (mc *GreeterProxyGreetCall) ArgName(argIndex int) string {
+	return [...]string{"ctx", "name"}[argIndex]
+}
+
+func (mc *GreeterProxyGreetCall) IsArgSensitive(argIndex int) bool {
+	return false
+}
+
+func (mc *GreeterProxyGreetCall) String() string { return proxyz.FormatMethodCall(mc) }
+
+func (mc *GreeterProxyGreetCall) Callee() interface{}          { return mc.callee }
+func (mc *GreeterProxyGreetCall) SetCallee(callee interface{}) { mc.callee = callee.(Greeter) }
+
+func (mc *GreeterProxyGreetCall) Clone() proxyz.MethodCall {
+	clone := *mc
+	clone.XxxInitClone(&clone)
+	return &clone
+}
+
+func (mc *GreeterProxyGreetCall) getNextInterceptor() (proxyz.MethodCallInterceptor, bool) {
+	if i := mc.nextInterceptorIndex; i < len(mc.interceptors) {
+		mc.nextInterceptorIndex++
+		return mc.interceptors[i], true
+	}
+
+	return nil, false
+}
+
+func (p *GreeterProxy) Greet(_ctx_ context.Context, _name_ string) (string, error) {
+	methodCallInterceptors := p.XxxResolveContextMethodCallInterceptors(_ctx_, p, GreeterProxyGreet)
+
+	if len(methodCallInterceptors) == 0 {
+		return p.Greeter.Greet(_ctx_, _name_)
+	}
+
+	methodCall := GreeterProxyGreetCall{
+		Arg0: _ctx_,
+		Arg1: _name_,
+
+		callee:       p.Greeter,
+		interceptors: methodCallInterceptors,
+	}
+
+	methodCall.XxxInit(&methodCall)
+	methodCall.Forward()
+	return methodCall.Result0, methodCall.Result1
+}
+
+func (p *GreeterProxy) XxxGetMethodName(methodIndex int) string {
+	return [...]string{
+		GreeterProxyGreet: "Greet",
+	}[methodIndex]
+}
+
+func (p *GreeterProxy) XxxNumberOfMethods() int { return 1 }
+func (p *GreeterProxy) XxxUnderlyingType() string {
+	return "github.com/roy2220/proxyz/internal/testproxy.Greeter"
+}
+
+func (p *GreeterProxy) XxxIsOfUnderlyingType(value interface{}) bool {
+	_, ok := value.(Greeter)
+	return ok
+}
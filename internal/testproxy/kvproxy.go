@@ -0,0 +1,441 @@
+// Code generated by proxyz. DO NOT EDIT.
+package testproxy
+
+import (
+	context "context"
+	proxyz "github.com/roy2220/proxyz"
+)
+
+type KVProxy struct {
+	proxyz.XxxProxyBase
+	KV
+}
+
+var _ = (proxyz.Proxy)((*KVProxy)(nil))
+
+func NewKVProxy(underlying KV) *KVProxy {
+	return &KVProxy{
+		KV: underlying,
+	}
+}
+
+const KVProxyGet = 0
+
+type KVProxyGetCall struct {
+	proxyz.XxxMethodCallBase
+
+	Arg0    context.Context
+	Arg1    string
+	Result0 string
+	Result1 error
+
+	callee               KV
+	interceptors         []proxyz.MethodCallInterceptor
+	nextInterceptorIndex int
+}
+
+var _ = (proxyz.MethodCall)((*KVProxyGetCall)(nil))
+
+func (mc *KVProxyGetCall) Forward() {
+	if interceptor, ok := mc.getNextInterceptor(); ok {
+		interceptor(mc)
+		return
+	}
+
+	mc.Result0, mc.Result1 = mc.callee.Get(mc.Arg0, mc.Arg1)
+}
+
+func (mc *KVProxyGetCall) GetArg(argIndex int) interface{} {
+	switch argIndex {
+	case 0:
+		return mc.Arg0
+	case 1:
+		return mc.Arg1
+	default:
+		panic("arg index out of range")
+	}
+}
+
+func (mc *KVProxyGetCall) SetArg(argIndex int, arg interface{}) {
+	switch argIndex {
+	case 0:
+		if arg == nil {
+			mc.Arg0 = *new(context.Context)
+		} else {
+			mc.Arg0 = arg.(context.Context)
+		}
+	case 1:
+		if arg == nil {
+			mc.Arg1 = *new(string)
+		} else {
+			mc.Arg1 = arg.(string)
+		}
+	default:
+		panic("arg index out of range")
+	}
+}
+
+func (mc *KVProxyGetCall) GetResult(resultIndex int) interface{} {
+	switch resultIndex {
+	case 0:
+		return mc.Result0
+	case 1:
+		return mc.Result1
+	default:
+		panic("result index out of range")
+	}
+}
+
+func (mc *KVProxyGetCall) SetResult(resultIndex int, result interface{}) {
+	switch resultIndex {
+	case 0:
+		if result == nil {
+			mc.Result0 = *new(string)
+		} else {
+			mc.Result0 = result.(string)
+		}
+	case 1:
+		if result == nil {
+			mc.Result1 = *new(error)
+		} else {
+			mc.Result1 = result.(error)
+		}
+	default:
+		panic("result index out of range")
+	}
+}
+
+func (mc *KVProxyGetCall) UnderlyingType() string {
+	return "github.com/roy2220/proxyz/internal/testproxy.KV"
+}
+func (mc *KVProxyGetCall) MethodName() string    { return "Get" }
+func (mc *KVProxyGetCall) MethodIndex() int      { return KVProxyGet }
+func (mc *KVProxyGetCall) NumberOfArgs() int     { return 2 }
+func (mc *KVProxyGetCall) NumberOfResults() int  { return 2 }
+func (mc *KVProxyGetCall) ContextArgIndex() int  { return 0 }
+func (mc *KVProxyGetCall) ErrorResultIndex() int { return 1 }
+
+func (mc *KVProxyGetCall) ArgName(argIndex int) string {
+	return [...]string{"ctx", "key"}[argIndex]
+}
+
+func (mc *KVProxyGetCall) IsArgSensitive(argIndex int) bool {
+	return false
+}
+
+func (mc *KVProxyGetCall) String() string { return proxyz.FormatMethodCall(mc) }
+
+func (mc *KVProxyGetCall) Callee() interface{}          { return mc.callee }
+func (mc *KVProxyGetCall) SetCallee(callee interface{}) { mc.callee = callee.(KV) }
+
+func (mc *KVProxyGetCall) Clone() proxyz.MethodCall {
+	clone := *mc
+	clone.XxxInitClone(&clone)
+	return &clone
+}
+
+func (mc *KVProxyGetCall) getNextInterceptor() (proxyz.MethodCallInterceptor, bool) {
+	if i := mc.nextInterceptorIndex; i < len(mc.interceptors) {
+		mc.nextInterceptorIndex++
+		return mc.interceptors[i], true
+	}
+
+	return nil, false
+}
+
+func (p *KVProxy) Get(_ctx_ context.Context, _key_ string) (string, error) {
+	methodCallInterceptors := p.XxxResolveContextMethodCallInterceptors(_ctx_, p, KVProxyGet)
+
+	if len(methodCallInterceptors) == 0 {
+		return p.KV.Get(_ctx_, _key_)
+	}
+
+	methodCall := KVProxyGetCall{
+		Arg0: _ctx_,
+		Arg1: _key_,
+
+		callee:       p.KV,
+		interceptors: methodCallInterceptors,
+	}
+
+	methodCall.XxxInit(&methodCall)
+	methodCall.Forward()
+	return methodCall.Result0, methodCall.Result1
+}
+
+const KVProxyKeys = 1
+
+type KVProxyKeysCall struct {
+	proxyz.XxxMethodCallBase
+
+	Result0 []string
+
+	callee               KV
+	interceptors         []proxyz.MethodCallInterceptor
+	nextInterceptorIndex int
+}
+
+var _ = (proxyz.MethodCall)((*KVProxyKeysCall)(nil))
+
+func (mc *KVProxyKeysCall) Forward() {
+	if interceptor, ok := mc.getNextInterceptor(); ok {
+		interceptor(mc)
+		return
+	}
+
+	mc.Result0 = mc.callee.Keys()
+}
+
+func (mc *KVProxyKeysCall) GetArg(argIndex int) interface{} {
+	switch argIndex {
+	default:
+		panic("arg index out of range")
+	}
+}
+
+func (mc *KVProxyKeysCall) SetArg(argIndex int, arg interface{}) {
+	switch argIndex {
+	default:
+		panic("arg index out of range")
+	}
+}
+
+func (mc *KVProxyKeysCall) GetResult(resultIndex int) interface{} {
+	switch resultIndex {
+	case 0:
+		return mc.Result0
+	default:
+		panic("result index out of range")
+	}
+}
+
+func (mc *KVProxyKeysCall) SetResult(resultIndex int, result interface{}) {
+	switch resultIndex {
+	case 0:
+		if result == nil {
+			mc.Result0 = *new([]string)
+		} else {
+			mc.Result0 = result.([]string)
+		}
+	default:
+		panic("result index out of range")
+	}
+}
+
+func (mc *KVProxyKeysCall) UnderlyingType() string {
+	return "github.com/roy2220/proxyz/internal/testproxy.KV"
+}
+func (mc *KVProxyKeysCall) MethodName() string    { return "Keys" }
+func (mc *KVProxyKeysCall) MethodIndex() int      { return KVProxyKeys }
+func (mc *KVProxyKeysCall) NumberOfArgs() int     { return 0 }
+func (mc *KVProxyKeysCall) NumberOfResults() int  { return 1 }
+func (mc *KVProxyKeysCall) ContextArgIndex() int  { return -1 }
+func (mc *KVProxyKeysCall) ErrorResultIndex() int { return -1 }
+
+func (mc *KVProxyKeysCall) ArgName(argIndex int) string {
+	return [...]string{}[argIndex]
+}
+
+func (mc *KVProxyKeysCall) IsArgSensitive(argIndex int) bool {
+	return false
+}
+
+func (mc *KVProxyKeysCall) String() string { return proxyz.FormatMethodCall(mc) }
+
+func (mc *KVProxyKeysCall) Callee() interface{}          { return mc.callee }
+func (mc *KVProxyKeysCall) SetCallee(callee interface{}) { mc.callee = callee.(KV) }
+
+func (mc *KVProxyKeysCall) Clone() proxyz.MethodCall {
+	clone := *mc
+	clone.XxxInitClone(&clone)
+	return &clone
+}
+
+func (mc *KVProxyKeysCall) getNextInterceptor() (proxyz.MethodCallInterceptor, bool) {
+	if i := mc.nextInterceptorIndex; i < len(mc.interceptors) {
+		mc.nextInterceptorIndex++
+		return mc.interceptors[i], true
+	}
+
+	return nil, false
+}
+
+func (p *KVProxy) Keys() []string {
+	methodCallInterceptors := p.XxxResolveMethodCallInterceptors(p, KVProxyKeys)
+
+	if len(methodCallInterceptors) == 0 {
+		return p.KV.Keys()
+	}
+
+	methodCall := KVProxyKeysCall{
+		callee:       p.KV,
+		interceptors: methodCallInterceptors,
+	}
+
+	methodCall.XxxInit(&methodCall)
+	methodCall.Forward()
+	return methodCall.Result0
+}
+
+const KVProxySet = 2
+
+type KVProxySetCall struct {
+	proxyz.XxxMethodCallBase
+
+	Arg0    context.Context
+	Arg1    string
+	Arg2    string
+	Result0 error
+
+	callee               KV
+	interceptors         []proxyz.MethodCallInterceptor
+	nextInterceptorIndex int
+}
+
+var _ = (proxyz.MethodCall)((*KVProxySetCall)(nil))
+
+func (mc *KVProxySetCall) Forward() {
+	if interceptor, ok := mc.getNextInterceptor(); ok {
+		interceptor(mc)
+		return
+	}
+
+	mc.Result0 = mc.callee.Set(mc.Arg0, mc.Arg1, mc.Arg2)
+}
+
+func (mc *KVProxySetCall) GetArg(argIndex int) interface{} {
+	switch argIndex {
+	case 0:
+		return mc.Arg0
+	case 1:
+		return mc.Arg1
+	case 2:
+		return mc.Arg2
+	default:
+		panic("arg index out of range")
+	}
+}
+
+func (mc *KVProxySetCall) SetArg(argIndex int, arg interface{}) {
+	switch argIndex {
+	case 0:
+		if arg == nil {
+			mc.Arg0 = *new(context.Context)
+		} else {
+			mc.Arg0 = arg.(context.Context)
+		}
+	case 1:
+		if arg == nil {
+			mc.Arg1 = *new(string)
+		} else {
+			mc.Arg1 = arg.(string)
+		}
+	case 2:
+		if arg == nil {
+			mc.Arg2 = *new(string)
+		} else {
+			mc.Arg2 = arg.(string)
+		}
+	default:
+		panic("arg index out of range")
+	}
+}
+
+func (mc *KVProxySetCall) GetResult(resultIndex int) interface{} {
+	switch resultIndex {
+	case 0:
+		return mc.Result0
+	default:
+		panic("result index out of range")
+	}
+}
+
+func (mc *KVProxySetCall) SetResult(resultIndex int, result interface{}) {
+	switch resultIndex {
+	case 0:
+		if result == nil {
+			mc.Result0 = *new(error)
+		} else {
+			mc.Result0 = result.(error)
+		}
+	default:
+		panic("result index out of range")
+	}
+}
+
+func (mc *KVProxySetCall) UnderlyingType() string {
+	return "github.com/roy2220/proxyz/internal/testproxy.KV"
+}
+func (mc *KVProxySetCall) MethodName() string    { return "Set" }
+func (mc *KVProxySetCall) MethodIndex() int      { return KVProxySet }
+func (mc *KVProxySetCall) NumberOfArgs() int     { return 3 }
+func (mc *KVProxySetCall) NumberOfResults() int  { return 1 }
+func (mc *KVProxySetCall) ContextArgIndex() int  { return 0 }
+func (mc *KVProxySetCall) ErrorResultIndex() int { return 0 }
+
+func (mc *KVProxySetCall) ArgName(argIndex int) string {
+	return [...]string{"ctx", "key", "value"}[argIndex]
+}
+
+func (mc *KVProxySetCall) IsArgSensitive(argIndex int) bool {
+	return false
+}
+
+func (mc *KVProxySetCall) String() string { return proxyz.FormatMethodCall(mc) }
+
+func (mc *KVProxySetCall) Callee() interface{}          { return mc.callee }
+func (mc *KVProxySetCall) SetCallee(callee interface{}) { mc.callee = callee.(KV) }
+
+func (mc *KVProxySetCall) Clone() proxyz.MethodCall {
+	clone := *mc
+	clone.XxxInitClone(&clone)
+	return &clone
+}
+
+func (mc *KVProxySetCall) getNextInterceptor() (proxyz.MethodCallInterceptor, bool) {
+	if i := mc.nextInterceptorIndex; i < len(mc.interceptors) {
+		mc.nextInterceptorIndex++
+		return mc.interceptors[i], true
+	}
+
+	return nil, false
+}
+
+func (p *KVProxy) Set(_ctx_ context.Context, _key_ string, _value_ string) error {
+	methodCallInterceptors := p.XxxResolveContextMethodCallInterceptors(_ctx_, p, KVProxySet)
+
+	if len(methodCallInterceptors) == 0 {
+		return p.KV.Set(_ctx_, _key_, _value_)
+	}
+
+	methodCall := KVProxySetCall{
+		Arg0: _ctx_,
+		Arg1: _key_,
+		Arg2: _value_,
+
+		callee:       p.KV,
+		interceptors: methodCallInterceptors,
+	}
+
+	methodCall.XxxInit(&methodCall)
+	methodCall.Forward()
+	return methodCall.Result0
+}
+
+func (p *KVProxy) XxxGetMethodName(methodIndex int) string {
+	return [...]string{
+		KVProxyGet:  "Get",
+		KVProxyKeys: "Keys",
+		KVProxySet:  "Set",
+	}[methodIndex]
+}
+
+func (p *KVProxy) XxxNumberOfMethods() int { return 3 }
+func (p *KVProxy) XxxUnderlyingType() string {
+	return "github.com/roy2220/proxyz/internal/testproxy.KV"
+}
+
+func (p *KVProxy) XxxIsOfUnderlyingType(value interface{}) bool {
+	_, ok := value.(KV)
+	return ok
+}
@@ -0,0 +1,276 @@
+// Code generated by proxyz. DO NOT EDIT.
+package testproxy
+
+import (
+	proxyz "github.com/roy2220/proxyz"
+)
+
+type SorterProxy struct {
+	proxyz.XxxProxyBase
+	Sorter
+}
+
+var _ = (proxyz.Proxy)((*SorterProxy)(nil))
+
+func NewSorterProxy(underlying Sorter) *SorterProxy {
+	return &SorterProxy{
+		Sorter: underlying,
+	}
+}
+
+const SorterProxySort = 0
+
+type SorterProxySortCall struct {
+	proxyz.XxxMethodCallBase
+
+	Arg0    []string
+	Result0 []string
+
+	callee               Sorter
+	interceptors         []proxyz.MethodCallInterceptor
+	nextInterceptorIndex int
+}
+
+var _ = (proxyz.MethodCall)((*SorterProxySortCall)(nil))
+
+func (mc *SorterProxySortCall) Forward() {
+	if interceptor, ok := mc.getNextInterceptor(); ok {
+		interceptor(mc)
+		return
+	}
+
+	mc.Result0 = mc.callee.Sort(mc.Arg0)
+}
+
+func (mc *SorterProxySortCall) GetArg(argIndex int) interface{} {
+	switch argIndex {
+	case 0:
+		return mc.Arg0
+	default:
+		panic("arg index out of range")
+	}
+}
+
+func (mc *SorterProxySortCall) SetArg(argIndex int, arg interface{}) {
+	switch argIndex {
+	case 0:
+		if arg == nil {
+			mc.Arg0 = *new([]string)
+		} else {
+			mc.Arg0 = arg.([]string)
+		}
+	default:
+		panic("arg index out of range")
+	}
+}
+
+func (mc *SorterProxySortCall) GetResult(resultIndex int) interface{} {
+	switch resultIndex {
+	case 0:
+		return mc.Result0
+	default:
+		panic("result index out of range")
+	}
+}
+
+func (mc *SorterProxySortCall) SetResult(resultIndex int, result interface{}) {
+	switch resultIndex {
+	case 0:
+		if result == nil {
+			mc.Result0 = *new([]string)
+		} else {
+			mc.Result0 = result.([]string)
+		}
+	default:
+		panic("result index out of range")
+	}
+}
+
+func (mc *SorterProxySortCall) UnderlyingType() string {
+	return "github.com/roy2220/proxyz/internal/testproxy.Sorter"
+}
+func (mc *SorterProxySortCall) MethodName() string    { return "Sort" }
+func (mc *SorterProxySortCall) MethodIndex() int      { return SorterProxySort }
+func (mc *SorterProxySortCall) NumberOfArgs() int     { return 1 }
+func (mc *SorterProxySortCall) NumberOfResults() int  { return 1 }
+func (mc *SorterProxySortCall) ContextArgIndex() int  { return -1 }
+func (mc *SorterProxySortCall) ErrorResultIndex() int { return -1 }
+
+func (mc *SorterProxySortCall) ArgName(argIndex int) string {
+	return [...]string{"values"}[argIndex]
+}
+
+func (mc *SorterProxySortCall) IsArgSensitive(argIndex int) bool {
+	return false
+}
+
+func (mc *SorterProxySortCall) String() string { return proxyz.FormatMethodCall(mc) }
+
+func (mc *SorterProxySortCall) Callee() interface{}          { return mc.callee }
+func (mc *SorterProxySortCall) SetCallee(callee interface{}) { mc.callee = callee.(Sorter) }
+
+func (mc *SorterProxySortCall) Clone() proxyz.MethodCall {
+	clone := *mc
+	clone.XxxInitClone(&clone)
+	return &clone
+}
+
+func (mc *SorterProxySortCall) getNextInterceptor() (proxyz.MethodCallInterceptor, bool) {
+	if i := mc.nextInterceptorIndex; i < len(mc.interceptors) {
+		mc.nextInterceptorIndex++
+		return mc.interceptors[i], true
+	}
+
+	return nil, false
+}
+
+func (p *SorterProxy) Sort(_values_ []string) []string {
+	methodCallInterceptors := p.XxxResolveMethodCallInterceptors(p, SorterProxySort)
+
+	if len(methodCallInterceptors) == 0 {
+		return p.Sorter.Sort(_values_)
+	}
+
+	methodCall := SorterProxySortCall{
+		Arg0: _values_,
+
+		callee:       p.Sorter,
+		interceptors: methodCallInterceptors,
+	}
+
+	methodCall.XxxInit(&methodCall)
+	methodCall.Forward()
+	return methodCall.Result0
+}
+
+const SorterProxySortInPlace = 1
+
+type SorterProxySortInPlaceCall struct {
+	proxyz.XxxMethodCallBase
+
+	Arg0 []string
+
+	callee               Sorter
+	interceptors         []proxyz.MethodCallInterceptor
+	nextInterceptorIndex int
+}
+
+var _ = (proxyz.MethodCall)((*SorterProxySortInPlaceCall)(nil))
+
+func (mc *SorterProxySortInPlaceCall) Forward() {
+	if interceptor, ok := mc.getNextInterceptor(); ok {
+		interceptor(mc)
+		return
+	}
+
+	mc.callee.SortInPlace(mc.Arg0)
+}
+
+func (mc *SorterProxySortInPlaceCall) GetArg(argIndex int) interface{} {
+	switch argIndex {
+	case 0:
+		return mc.Arg0
+	default:
+		panic("arg index out of range")
+	}
+}
+
+func (mc *SorterProxySortInPlaceCall) SetArg(argIndex int, arg interface{}) {
+	switch argIndex {
+	case 0:
+		if arg == nil {
+			mc.Arg0 = *new([]string)
+		} else {
+			mc.Arg0 = arg.([]string)
+		}
+	default:
+		panic("arg index out of range")
+	}
+}
+
+func (mc *SorterProxySortInPlaceCall) GetResult(resultIndex int) interface{} {
+	switch resultIndex {
+	default:
+		panic("result index out of range")
+	}
+}
+
+func (mc *SorterProxySortInPlaceCall) SetResult(resultIndex int, result interface{}) {
+	switch resultIndex {
+	default:
+		panic("result index out of range")
+	}
+}
+
+func (mc *SorterProxySortInPlaceCall) UnderlyingType() string {
+	return "github.com/roy2220/proxyz/internal/testproxy.Sorter"
+}
+func (mc *SorterProxySortInPlaceCall) MethodName() string    { return "SortInPlace" }
+func (mc *SorterProxySortInPlaceCall) MethodIndex() int      { return SorterProxySortInPlace }
+func (mc *SorterProxySortInPlaceCall) NumberOfArgs() int     { return 1 }
+func (mc *SorterProxySortInPlaceCall) NumberOfResults() int  { return 0 }
+func (mc *SorterProxySortInPlaceCall) ContextArgIndex() int  { return -1 }
+func (mc *SorterProxySortInPlaceCall) ErrorResultIndex() int { return -1 }
+
+func (mc *SorterProxySortInPlaceCall) ArgName(argIndex int) string {
+	return [...]string{"values"}[argIndex]
+}
+
+func (mc *SorterProxySortInPlaceCall) IsArgSensitive(argIndex int) bool {
+	return false
+}
+
+func (mc *SorterProxySortInPlaceCall) String() string { return proxyz.FormatMethodCall(mc) }
+
+func (mc *SorterProxySortInPlaceCall) Callee() interface{}          { return mc.callee }
+func (mc *SorterProxySortInPlaceCall) SetCallee(callee interface{}) { mc.callee = callee.(Sorter) }
+
+func (mc *SorterProxySortInPlaceCall) Clone() proxyz.MethodCall {
+	clone := *mc
+	clone.XxxInitClone(&clone)
+	return &clone
+}
+
+func (mc *SorterProxySortInPlaceCall) getNextInterceptor() (proxyz.MethodCallInterceptor, bool) {
+	if i := mc.nextInterceptorIndex; i < len(mc.interceptors) {
+		mc.nextInterceptorIndex++
+		return mc.interceptors[i], true
+	}
+
+	return nil, false
+}
+
+func (p *SorterProxy) SortInPlace(_values_ []string) {
+	methodCallInterceptors := p.XxxResolveMethodCallInterceptors(p, SorterProxySortInPlace)
+
+	if len(methodCallInterceptors) == 0 {
+		p.Sorter.SortInPlace(_values_)
+	}
+
+	methodCall := SorterProxySortInPlaceCall{
+		Arg0: _values_,
+
+		callee:       p.Sorter,
+		interceptors: methodCallInterceptors,
+	}
+
+	methodCall.XxxInit(&methodCall)
+	methodCall.Forward()
+}
+
+func (p *SorterProxy) XxxGetMethodName(methodIndex int) string {
+	return [...]string{
+		SorterProxySort:        "Sort",
+		SorterProxySortInPlace: "SortInPlace",
+	}[methodIndex]
+}
+
+func (p *SorterProxy) XxxNumberOfMethods() int { return 2 }
+func (p *SorterProxy) XxxUnderlyingType() string {
+	return "github.com/roy2220/proxyz/internal/testproxy.Sorter"
+}
+
+func (p *SorterProxy) XxxIsOfUnderlyingType(value interface{}) bool {
+	_, ok := value.(Sorter)
+	return ok
+}
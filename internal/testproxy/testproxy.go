@@ -0,0 +1,168 @@
+// Package testproxy provides proxies generated for testing.
+package testproxy
+
+import (
+	"context"
+	"errors"
+	"sort"
+)
+
+//go:generate go run github.com/roy2220/proxyz/cmd/proxyz . KV . KVProxy -w kvproxy.go
+//go:generate go run github.com/roy2220/proxyz/cmd/proxyz . Greeter . GreeterProxy -w greeterproxy.go
+//go:generate go run github.com/roy2220/proxyz/cmd/proxyz . AccountStore . AccountStoreProxy -w accountstoreproxy.go --redact *password
+//go:generate go run github.com/roy2220/proxyz/cmd/proxyz . Sorter . SorterProxy -w sorterproxy.go
+
+// KV represents a key-value store.
+type KV interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value string) error
+	Keys() []string
+}
+
+// ErrNotFound is returned by KV.Get when the key is not found.
+var ErrNotFound = errors.New("testproxy: not found")
+
+// MapKV is an implementation of KV based on a map.
+type MapKV map[string]string
+
+var _ = (KV)(MapKV(nil))
+
+// Get implements KV.Get.
+func (mkv MapKV) Get(ctx context.Context, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	value, ok := mkv[key]
+
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return value, nil
+}
+
+// Set implements KV.Set.
+func (mkv MapKV) Set(ctx context.Context, key string, value string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	mkv[key] = value
+	return nil
+}
+
+// Keys implements KV.Keys.
+func (mkv MapKV) Keys() []string {
+	keys := make([]string, 0, len(mkv))
+
+	for key := range mkv {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// Greeter represents a greeter.
+type Greeter interface {
+	Greet(ctx context.Context, name string) (string, error)
+}
+
+// KVGreeter is an implementation of Greeter getting the greeting from a KV
+// with the key "greeting".
+type KVGreeter struct {
+	KV KV
+}
+
+var _ = (Greeter)(KVGreeter{})
+
+// Greet implements Greeter.Greet.
+func (kvg KVGreeter) Greet(ctx context.Context, name string) (string, error) {
+	greeting, err := kvg.KV.Get(ctx, "greeting")
+
+	if err != nil {
+		return "", err
+	}
+
+	return greeting + ", " + name, nil
+}
+
+// Account represents an account.
+type Account struct {
+	Name     string   `validate:"required,min=3,max=16,regexp=^[a-z]+$"`
+	Role     string   `validate:"oneof=admin user"`
+	Tags     []string `validate:"max=2"`
+	Password string
+}
+
+// AccountStore represents an account store.
+type AccountStore interface {
+	CreateAccount(ctx context.Context, account *Account) error
+	CountAccounts() int
+
+	//proxyz:redact code
+	Login(ctx context.Context, name string, password string, code string) error
+}
+
+// ErrLoginFailed is returned by AccountStore.Login when the name or password
+// is incorrect.
+var ErrLoginFailed = errors.New("testproxy: login failed")
+
+// MapAccountStore is an implementation of AccountStore based on a map.
+type MapAccountStore map[string]*Account
+
+var _ = (AccountStore)(MapAccountStore(nil))
+
+// CreateAccount implements AccountStore.CreateAccount.
+func (mas MapAccountStore) CreateAccount(ctx context.Context, account *Account) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	mas[account.Name] = account
+	return nil
+}
+
+// CountAccounts implements AccountStore.CountAccounts.
+func (mas MapAccountStore) CountAccounts() int {
+	return len(mas)
+}
+
+// Login implements AccountStore.Login.
+func (mas MapAccountStore) Login(ctx context.Context, name string, password string, code string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	account, ok := mas[name]
+
+	if !ok || account.Password != password {
+		return ErrLoginFailed
+	}
+
+	return nil
+}
+
+// Sorter represents a sorter of strings.
+type Sorter interface {
+	Sort(values []string) []string
+	SortInPlace(values []string)
+}
+
+// AliasingSorter is an implementation of Sorter, whose Sort mistakenly sorts
+// the given values in place.
+type AliasingSorter struct{}
+
+var _ = (Sorter)(AliasingSorter{})
+
+// Sort implements Sorter.Sort.
+func (AliasingSorter) Sort(values []string) []string {
+	sort.Strings(values)
+	return values
+}
+
+// SortInPlace implements Sorter.SortInPlace.
+func (AliasingSorter) SortInPlace(values []string) {
+	sort.Strings(values)
+}
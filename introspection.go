@@ -0,0 +1,120 @@
+package proxyz
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+)
+
+// InterceptorInfo represents the information of an interceptor applied to
+// the calls to a method.
+type InterceptorInfo struct {
+	// Name is the name of the interceptor, or "" if unnamed.
+	Name string
+
+	Priority int
+
+	// IsGlobal indicates whether the interceptor is a global one.
+	IsGlobal bool
+
+	// CallCount is the number of the method calls intercepted, which stays
+	// zero unless the stats are enabled (see EnableInterceptorStats).
+	CallCount uint64
+
+	// TotalTime is the accumulated time spent in the interceptor, including
+	// the time spent in the interceptors called after it and in the method,
+	// which stays zero unless the stats are enabled likewise.
+	TotalTime time.Duration
+}
+
+// XxxDescribeMethodCallInterceptors implements Proxy.XxxDescribeMethodCallInterceptors.
+func (pb *XxxProxyBase) XxxDescribeMethodCallInterceptors(methodIndex int) []InterceptorInfo {
+	pb.mutex.Lock()
+	defer pb.mutex.Unlock()
+	return describeInterceptors(pb.methodCallInterceptors.GetEntries(methodIndex), false)
+}
+
+func (pb *XxxProxyBase) hasEventBus() bool {
+	eventBus, _ := pb.eventBus.Load().(*EventBus)
+	return eventBus != nil
+}
+
+// DescribeMethodCallInterceptors returns the information of all the
+// interceptors applied to the calls to the method at the given index of the
+// given proxy, including the global interceptors, in the order they are
+// called.
+func DescribeMethodCallInterceptors(proxy Proxy, methodIndex int) []InterceptorInfo {
+	underlyingType, methodName := proxy.XxxUnderlyingType(), proxy.XxxGetMethodName(methodIndex)
+	globalInterceptorsMutex.Lock()
+	var globalEntries []interceptorEntry
+
+	for _, entry := range allGlobalInterceptors.GetEntries(0) {
+		if entry.Filter == nil || entry.Filter(underlyingType, methodName) {
+			globalEntries = append(globalEntries, entry)
+		}
+	}
+
+	globalInterceptorsMutex.Unlock()
+	infos := describeInterceptors(globalEntries, true)
+	return append(infos, proxy.XxxDescribeMethodCallInterceptors(methodIndex)...)
+}
+
+// DumpInterceptors writes the information of all the interceptors applied to
+// the calls to all the methods of the given proxy in text to the given writer,
+// for debugging.
+func DumpInterceptors(writer io.Writer, proxy Proxy) error {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "%s:\n", proxy.XxxUnderlyingType())
+
+	if proxy, ok := proxy.(interface{ hasEventBus() bool }); ok && proxy.hasEventBus() {
+		buffer.WriteString("\t(event bus attached)\n")
+	}
+
+	for methodIndex, n := 0, proxy.XxxNumberOfMethods(); methodIndex < n; methodIndex++ {
+		fmt.Fprintf(&buffer, "\t%s:\n", proxy.XxxGetMethodName(methodIndex))
+		infos := DescribeMethodCallInterceptors(proxy, methodIndex)
+
+		if len(infos) == 0 {
+			buffer.WriteString("\t\t(none)\n")
+			continue
+		}
+
+		for i := range infos {
+			info := &infos[i]
+			name := info.Name
+
+			if name == "" {
+				name = "(unnamed)"
+			}
+
+			if info.IsGlobal {
+				name += " [global]"
+			}
+
+			fmt.Fprintf(&buffer, "\t\t%d. %s priority=%d calls=%d totalTime=%v\n",
+				i+1, name, info.Priority, info.CallCount, info.TotalTime)
+		}
+	}
+
+	_, err := buffer.WriteTo(writer)
+	return err
+}
+
+func describeInterceptors(entries []interceptorEntry, isGlobal bool) []InterceptorInfo {
+	infos := make([]InterceptorInfo, len(entries))
+
+	for i := range entries {
+		entry := &entries[i]
+
+		infos[i] = InterceptorInfo{
+			Name:      entry.Options.Name,
+			Priority:  entry.Options.Priority,
+			IsGlobal:  isGlobal,
+			CallCount: entry.Stats.CallCount(),
+			TotalTime: entry.Stats.TotalTime(),
+		}
+	}
+
+	return infos
+}
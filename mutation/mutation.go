@@ -0,0 +1,153 @@
+// Package mutation provides an interceptor detecting the mutations of the
+// arguments made by method calls, for debugging aliasing bugs, e.g. a
+// caller-owned slice modified by the underlying implementation.
+//
+// The arguments are deeply snapshotted before the method calls and compared
+// afterwards, which is expensive, so the interceptor is intended for
+// debugging and testing only.
+package mutation
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+
+	"github.com/roy2220/proxyz"
+	"github.com/roy2220/proxyz/internal/reporting"
+)
+
+// Mutation represents a mutation of an argument made by a method call.
+type Mutation struct {
+	UnderlyingType string
+	MethodName     string
+	ArgIndex       int
+	ArgName        string
+
+	// Diff describes the changes of the argument, one per line, e.g.
+	// `[0]: "b" -> "a"`. The values of the sensitive arguments are redacted.
+	Diff []string
+}
+
+// WriteTo writes the mutation in text to the given writer.
+func (m *Mutation) WriteTo(writer io.Writer) (int64, error) {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "mutation: argument mutated; method=%s.%s argIndex=%d argName=%q\n",
+		m.UnderlyingType, m.MethodName, m.ArgIndex, m.ArgName)
+
+	for _, line := range m.Diff {
+		fmt.Fprintf(&buffer, "\t%s\n", line)
+	}
+
+	return buffer.WriteTo(writer)
+}
+
+// Options represents options for detectors.
+type Options struct {
+	// MutableArgs are the patterns, in the syntax of path.Match, of the
+	// arguments allowed to be mutated, in the form of "MethodName.argName",
+	// e.g. "Read.p". Unnamed arguments are named "argN", N being the
+	// argument index.
+	MutableArgs []string
+
+	// Reporter reports the mutations detected. If not specified, the
+	// mutations will be written to stderr.
+	Reporter func(mutation *Mutation)
+}
+
+// Detector detects the mutations of the arguments made by method calls.
+type Detector struct {
+	options Options
+}
+
+// Init initializes the detector with the given options and returns it.
+func (d *Detector) Init(options Options) *Detector {
+	for _, mutableArg := range options.MutableArgs {
+		if _, err := path.Match(mutableArg, ""); err != nil {
+			panic(errors.New("mutation: invalid mutable arg pattern: " + mutableArg))
+		}
+	}
+
+	if options.Reporter == nil {
+		options.Reporter = func(mutation *Mutation) { reporting.WriteToStderr(mutation) }
+	}
+
+	d.options = options
+	return d
+}
+
+// Detect adds an interceptor to the given proxy to detect the mutations of
+// the arguments made by the calls to all the methods of the proxy.
+func (d *Detector) Detect(proxy proxyz.Proxy) {
+	proxyz.InterceptAllMethodCalls(proxy, d.NewMethodCallInterceptor(proxy.XxxUnderlyingType()))
+}
+
+// NewMethodCallInterceptor returns an interceptor detecting the mutations of
+// the arguments made by the method calls of the given underlying type.
+func (d *Detector) NewMethodCallInterceptor(underlyingType string) proxyz.MethodCallInterceptor {
+	return func(methodCall proxyz.MethodCall) {
+		var argEntries []argEntry
+
+		for i, n := 0, methodCall.NumberOfArgs(); i < n; i++ {
+			if i == methodCall.ContextArgIndex() || d.argIsMutable(methodCall, i) {
+				continue
+			}
+
+			arg := methodCall.GetArg(i)
+
+			argEntries = append(argEntries, argEntry{
+				Index:    i,
+				Value:    arg,
+				Snapshot: takeSnapshot(arg),
+			})
+		}
+
+		methodCall.Forward()
+
+		for _, argEntry := range argEntries {
+			diff := argEntry.Snapshot.Diff(takeSnapshot(argEntry.Value), methodCall.IsArgSensitive(argEntry.Index))
+
+			if len(diff) == 0 {
+				continue
+			}
+
+			d.options.Reporter(&Mutation{
+				UnderlyingType: underlyingType,
+				MethodName:     methodCall.MethodName(),
+				ArgIndex:       argEntry.Index,
+				ArgName:        methodCall.ArgName(argEntry.Index),
+				Diff:           diff,
+			})
+		}
+	}
+}
+
+func (d *Detector) argIsMutable(methodCall proxyz.MethodCall, argIndex int) bool {
+	if len(d.options.MutableArgs) == 0 {
+		return false
+	}
+
+	argName := methodCall.ArgName(argIndex)
+
+	if argName == "" {
+		argName = "arg" + strconv.Itoa(argIndex)
+	}
+
+	name := methodCall.MethodName() + "." + argName
+
+	for _, mutableArg := range d.options.MutableArgs {
+		if ok, _ := path.Match(mutableArg, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+type argEntry struct {
+	Index    int
+	Value    interface{}
+	Snapshot *snapshot
+}
@@ -0,0 +1,77 @@
+package mutation_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roy2220/proxyz"
+	"github.com/roy2220/proxyz/internal/testproxy"
+	"github.com/roy2220/proxyz/mutation"
+)
+
+func TestDetector(t *testing.T) {
+	var mutations []*mutation.Mutation
+	d := new(mutation.Detector).Init(mutation.Options{
+		MutableArgs: []string{"SortInPlace.values"},
+		Reporter:    func(mutation *mutation.Mutation) { mutations = append(mutations, mutation) },
+	})
+	sorter := testproxy.NewSorterProxy(testproxy.AliasingSorter{})
+	d.Detect(sorter)
+
+	values := []string{"b", "a"}
+	sorter.SortInPlace(values)
+	assert.Equal(t, []string{"a", "b"}, values)
+	assert.Empty(t, mutations)
+
+	values = []string{"b", "a"}
+	sorter.Sort(values)
+	require.Len(t, mutations, 1)
+	m := mutations[0]
+	assert.Equal(t, "github.com/roy2220/proxyz/internal/testproxy.Sorter", m.UnderlyingType)
+	assert.Equal(t, "Sort", m.MethodName)
+	assert.Equal(t, 0, m.ArgIndex)
+	assert.Equal(t, "values", m.ArgName)
+	assert.Equal(t, []string{`[0]: "b" -> "a"`, `[1]: "a" -> "b"`}, m.Diff)
+	var buffer bytes.Buffer
+	_, err := m.WriteTo(&buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, "mutation: argument mutated; method=github.com/roy2220/proxyz/internal/testproxy.Sorter.Sort argIndex=0 argName=\"values\"\n"+
+		"\t[0]: \"b\" -> \"a\"\n\t[1]: \"a\" -> \"b\"\n", buffer.String())
+}
+
+func TestDetectorDeep(t *testing.T) {
+	var mutations []*mutation.Mutation
+	d := new(mutation.Detector).Init(mutation.Options{
+		Reporter: func(mutation *mutation.Mutation) { mutations = append(mutations, mutation) },
+	})
+	as := testproxy.NewAccountStoreProxy(testproxy.MapAccountStore{
+		"roy": {Name: "roy", Password: "secret"},
+	})
+	d.Detect(as)
+	as.XxxInterceptMethodCall(testproxy.AccountStoreProxyCreateAccount, func(mc proxyz.MethodCall) {
+		account := mc.GetArg(1).(*testproxy.Account)
+		account.Tags[0] = "y"
+		account.Tags = append(account.Tags, "z")
+		mc.SetArg(1, &testproxy.Account{Name: "replaced"})
+		mc.Forward()
+	})
+
+	err := as.CreateAccount(context.Background(), &testproxy.Account{Name: "foo", Tags: []string{"x"}})
+	require.NoError(t, err)
+	require.Len(t, mutations, 1)
+	assert.Equal(t, "account", mutations[0].ArgName)
+	assert.Equal(t, []string{
+		`.Tags.len: 1 -> 2`,
+		`.Tags[0]: "x" -> "y"`,
+		`.Tags[1]: (added) -> "z"`,
+	}, mutations[0].Diff)
+
+	mutations = nil
+	err = as.Login(context.Background(), "roy", "secret", "123456")
+	require.NoError(t, err)
+	assert.Empty(t, mutations)
+}
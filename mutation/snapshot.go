@@ -0,0 +1,218 @@
+package mutation
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/roy2220/proxyz"
+)
+
+// snapshot represents a deep snapshot of a value, flattened into the paths
+// to the leaves and the representations of the leaves.
+type snapshot struct {
+	paths      []string
+	path2Value map[string]string
+}
+
+const maxSnapshotDepth = 32
+
+func takeSnapshot(value interface{}) *snapshot {
+	snapshotTaker := snapshotTaker{
+		snapshot: &snapshot{
+			path2Value: make(map[string]string),
+		},
+
+		visitedPointers: make(map[visitedPointer]struct{}),
+	}
+
+	snapshotTaker.Walk("", reflect.ValueOf(value), 0)
+	return snapshotTaker.snapshot
+}
+
+// Diff returns the changes from the snapshot to the given one, with the values
+// redacted if required.
+func (s *snapshot) Diff(other *snapshot, redacted bool) []string {
+	var diff []string
+
+	formatChange := func(path string, oldValue string, newValue string) string {
+		if path == "" {
+			path = "(root)"
+		}
+
+		return path + ": " + oldValue + " -> " + newValue
+	}
+
+	redact := func(value string) string {
+		if redacted {
+			return proxyz.Redacted
+		}
+
+		return value
+	}
+
+	for _, path := range s.paths {
+		oldValue := s.path2Value[path]
+
+		if newValue, ok := other.path2Value[path]; !ok {
+			diff = append(diff, formatChange(path, redact(oldValue), "(removed)"))
+		} else if newValue != oldValue {
+			diff = append(diff, formatChange(path, redact(oldValue), redact(newValue)))
+		}
+	}
+
+	for _, path := range other.paths {
+		if _, ok := s.path2Value[path]; !ok {
+			diff = append(diff, formatChange(path, "(added)", redact(other.path2Value[path])))
+		}
+	}
+
+	return diff
+}
+
+func (s *snapshot) addLeaf(path string, value string) {
+	s.paths = append(s.paths, path)
+	s.path2Value[path] = value
+}
+
+type snapshotTaker struct {
+	snapshot        *snapshot
+	visitedPointers map[visitedPointer]struct{}
+}
+
+type visitedPointer struct {
+	Type    reflect.Type
+	Address uintptr
+}
+
+func (st *snapshotTaker) Walk(path string, value reflect.Value, depth int) {
+	if depth > maxSnapshotDepth {
+		st.snapshot.addLeaf(path, "(too deep)")
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Invalid:
+		st.snapshot.addLeaf(path, "nil")
+	case reflect.Bool:
+		st.snapshot.addLeaf(path, strconv.FormatBool(value.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		st.snapshot.addLeaf(path, strconv.FormatInt(value.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		st.snapshot.addLeaf(path, strconv.FormatUint(value.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		st.snapshot.addLeaf(path, strconv.FormatFloat(value.Float(), 'g', -1, 64))
+	case reflect.Complex64, reflect.Complex128:
+		st.snapshot.addLeaf(path, fmt.Sprint(value.Complex()))
+	case reflect.String:
+		st.snapshot.addLeaf(path, strconv.Quote(value.String()))
+	case reflect.Ptr:
+		if value.IsNil() {
+			st.snapshot.addLeaf(path, "nil")
+			return
+		}
+
+		if !st.visitPointer(value) {
+			return
+		}
+
+		st.Walk(path, value.Elem(), depth+1)
+	case reflect.Interface:
+		if value.IsNil() {
+			st.snapshot.addLeaf(path, "nil")
+			return
+		}
+
+		st.Walk(path, value.Elem(), depth+1)
+	case reflect.Struct:
+		if value.NumField() == 0 {
+			st.snapshot.addLeaf(path, "{}")
+			return
+		}
+
+		for i, n := 0, value.NumField(); i < n; i++ {
+			st.Walk(path+"."+value.Type().Field(i).Name, value.Field(i), depth+1)
+		}
+	case reflect.Slice:
+		if value.IsNil() {
+			st.snapshot.addLeaf(path, "nil")
+			return
+		}
+
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			st.snapshot.addLeaf(path, fmt.Sprintf("%q", value.Bytes()))
+			return
+		}
+
+		st.walkElements(path, value, depth)
+	case reflect.Array:
+		st.walkElements(path, value, depth)
+	case reflect.Map:
+		if value.IsNil() {
+			st.snapshot.addLeaf(path, "nil")
+			return
+		}
+
+		if !st.visitPointer(value) {
+			return
+		}
+
+		st.snapshot.addLeaf(path+".len", strconv.Itoa(value.Len()))
+		keys := value.MapKeys()
+		keyPaths := make([]string, len(keys))
+
+		for i, key := range keys {
+			keyPaths[i] = path + "[" + formatMapKey(key) + "]"
+		}
+
+		sort.Sort(mapKeySorter{keys, keyPaths})
+
+		for i, key := range keys {
+			st.Walk(keyPaths[i], value.MapIndex(key), depth+1)
+		}
+	default:
+		// chan, func and unsafe pointer
+		st.snapshot.addLeaf(path, "("+value.Type().String()+")")
+	}
+}
+
+func (st *snapshotTaker) walkElements(path string, value reflect.Value, depth int) {
+	st.snapshot.addLeaf(path+".len", strconv.Itoa(value.Len()))
+
+	for i, n := 0, value.Len(); i < n; i++ {
+		st.Walk(path+"["+strconv.Itoa(i)+"]", value.Index(i), depth+1)
+	}
+}
+
+func (st *snapshotTaker) visitPointer(value reflect.Value) bool {
+	visitedPointer := visitedPointer{value.Type(), value.Pointer()}
+
+	if _, ok := st.visitedPointers[visitedPointer]; ok {
+		return false
+	}
+
+	st.visitedPointers[visitedPointer] = struct{}{}
+	return true
+}
+
+func formatMapKey(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return strconv.Quote(key.String())
+	}
+
+	return fmt.Sprintf("%v", key)
+}
+
+type mapKeySorter struct {
+	Keys     []reflect.Value
+	KeyPaths []string
+}
+
+func (mks mapKeySorter) Len() int           { return len(mks.Keys) }
+func (mks mapKeySorter) Less(i, j int) bool { return mks.KeyPaths[i] < mks.KeyPaths[j] }
+
+func (mks mapKeySorter) Swap(i, j int) {
+	mks.Keys[i], mks.Keys[j] = mks.Keys[j], mks.Keys[i]
+	mks.KeyPaths[i], mks.KeyPaths[j] = mks.KeyPaths[j], mks.KeyPaths[i]
+}
@@ -0,0 +1,98 @@
+package record
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// WriteChromeTrace writes the given method calls, as returned by
+// Recorder.Calls, to the given writer in Chrome trace-event JSON, which can
+// be viewed in chrome://tracing or Perfetto. Each method call becomes a
+// complete event on the thread of its goroutine, or a begin event if the
+// method call is in flight.
+func WriteChromeTrace(writer io.Writer, calls []Call) error {
+	trace := chromeTrace{
+		TraceEvents:     make([]chromeTraceEvent, 0, len(calls)),
+		DisplayTimeUnit: "ms",
+	}
+	pid := os.Getpid()
+
+	for i := range calls {
+		call := &calls[i]
+		event := chromeTraceEvent{
+			Name:      shortenType(call.UnderlyingType) + "." + call.MethodName,
+			Category:  call.UnderlyingType,
+			Phase:     "X",
+			Timestamp: microseconds(call.StartTime.Sub(time.Unix(0, 0))),
+			PID:       pid,
+			TID:       call.GoroutineID,
+			Args:      make(map[string]string, len(call.Args)+len(call.Results)+1),
+		}
+
+		if call.IsCompleted {
+			event.Duration = microseconds(call.Duration())
+		} else {
+			event.Phase = "B"
+		}
+
+		event.Args["callID"] = strconv.FormatUint(call.CallID, 10)
+
+		if call.ParentCallID != 0 {
+			event.Args["parentCallID"] = strconv.FormatUint(call.ParentCallID, 10)
+		}
+
+		for i, arg := range call.Args {
+			if i == call.ContextArgIndex {
+				continue
+			}
+
+			// e.g. "arg1.key", or "arg1" if unnamed
+			argKey := "arg" + strconv.Itoa(i)
+
+			if i < len(call.ArgNames) && call.ArgNames[i] != "" {
+				argKey += "." + call.ArgNames[i]
+			}
+
+			event.Args[argKey] = arg
+		}
+
+		for i, result := range call.Results {
+			event.Args["result"+strconv.Itoa(i)] = result
+		}
+
+		if call.PanicValue != "" {
+			event.Args["panic"] = call.PanicValue
+		}
+
+		if call.IsGoexited {
+			event.Args["goexit"] = "true"
+		}
+
+		trace.TraceEvents = append(trace.TraceEvents, event)
+	}
+
+	return json.NewEncoder(writer).Encode(&trace)
+}
+
+type chromeTrace struct {
+	TraceEvents     []chromeTraceEvent `json:"traceEvents"`
+	DisplayTimeUnit string             `json:"displayTimeUnit"`
+}
+
+type chromeTraceEvent struct {
+	Name      string            `json:"name"`
+	Category  string            `json:"cat"`
+	Phase     string            `json:"ph"`
+	Timestamp float64           `json:"ts"`
+	Duration  float64           `json:"dur,omitempty"`
+	PID       int               `json:"pid"`
+	TID       int64             `json:"tid"`
+	Args      map[string]string `json:"args"`
+}
+
+func microseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Microsecond)
+}
@@ -0,0 +1,165 @@
+// Package record provides an interceptor recording method calls, and the
+// exports of the calls recorded.
+package record
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/roy2220/proxyz"
+	"github.com/roy2220/proxyz/internal/goroutine"
+)
+
+// Call represents a method call recorded.
+type Call struct {
+	CallID uint64
+
+	// ParentCallID is the id of the nearest ancestor recorded of the method
+	// call, or 0 if there is none.
+	ParentCallID uint64
+
+	UnderlyingType string
+	MethodName     string
+
+	// Args are the arguments formatted by proxyz.FormatArgs.
+	Args []string
+
+	// ArgNames are the names of the arguments, "" for the unnamed ones.
+	ArgNames []string
+
+	// ContextArgIndex is the index of the context.Context argument, or -1
+	// if there is none.
+	ContextArgIndex int
+
+	// Results are the results formatted by proxyz.FormatResults.
+	Results []string
+
+	StartTime   time.Time
+	EndTime     time.Time
+	GoroutineID int64
+
+	// PanicValue is the value, formatted by fmt.Sprint, which the method
+	// call panicked with, if any, in which case Results is nil.
+	PanicValue string
+
+	// IsGoexited indicates whether the goroutine exited through
+	// runtime.Goexit during the method call, in which case Results is nil.
+	IsGoexited bool
+
+	// IsCompleted indicates whether the method call has completed, i.e.
+	// returned, panicked or goexited.
+	IsCompleted bool
+}
+
+// Duration returns the duration of the method call.
+func (c *Call) Duration() time.Duration {
+	return c.EndTime.Sub(c.StartTime)
+}
+
+// Recorder records method calls.
+type Recorder struct {
+	mutex        sync.Mutex
+	calls        []*Call
+	callID2Index map[uint64]int
+}
+
+// Init initializes the recorder and returns it.
+func (r *Recorder) Init() *Recorder {
+	r.callID2Index = make(map[uint64]int)
+	return r
+}
+
+// Record adds an interceptor to the given proxy to record the calls to all
+// the methods of the proxy.
+func (r *Recorder) Record(proxy proxyz.Proxy) {
+	proxyz.InterceptAllMethodCalls(proxy, r.NewMethodCallInterceptor(proxy.XxxUnderlyingType()))
+}
+
+// NewMethodCallInterceptor returns an interceptor recording the method calls
+// of the given underlying type.
+func (r *Recorder) NewMethodCallInterceptor(underlyingType string) proxyz.MethodCallInterceptor {
+	return func(methodCall proxyz.MethodCall) {
+		call := r.startCall(underlyingType, methodCall)
+
+		proxyz.ForwardAndObserve(methodCall, func(outcome proxyz.Outcome, panicValue interface{}) {
+			switch outcome {
+			case proxyz.Returned:
+				r.endCall(call, proxyz.FormatResults(methodCall), "", false)
+			case proxyz.Panicked:
+				r.endCall(call, nil, fmt.Sprint(panicValue), false)
+			default:
+				r.endCall(call, nil, "", true)
+			}
+		})
+	}
+}
+
+// Calls returns the copies of the method calls recorded, in the order they
+// started.
+func (r *Recorder) Calls() []Call {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	calls := make([]Call, len(r.calls))
+
+	for i, call := range r.calls {
+		calls[i] = *call
+	}
+
+	sort.SliceStable(calls, func(i, j int) bool {
+		return calls[i].CallID < calls[j].CallID
+	})
+
+	return calls
+}
+
+// Reset discards the method calls recorded.
+func (r *Recorder) Reset() {
+	r.mutex.Lock()
+	r.calls = nil
+	r.callID2Index = make(map[uint64]int)
+	r.mutex.Unlock()
+}
+
+func (r *Recorder) startCall(underlyingType string, methodCall proxyz.MethodCall) *Call {
+	call := Call{
+		CallID:          methodCall.CallID(),
+		UnderlyingType:  underlyingType,
+		MethodName:      methodCall.MethodName(),
+		Args:            proxyz.FormatArgs(methodCall),
+		ArgNames:        make([]string, methodCall.NumberOfArgs()),
+		ContextArgIndex: methodCall.ContextArgIndex(),
+		StartTime:       methodCall.StartTime(),
+		GoroutineID:     goroutine.CurrentID(),
+	}
+
+	for i := range call.ArgNames {
+		call.ArgNames[i] = methodCall.ArgName(i)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for parentCall := methodCall.ParentCall(); parentCall != nil; parentCall = parentCall.ParentCall() {
+		if _, ok := r.callID2Index[parentCall.CallID()]; ok {
+			call.ParentCallID = parentCall.CallID()
+			break
+		}
+	}
+
+	r.callID2Index[call.CallID] = len(r.calls)
+	r.calls = append(r.calls, &call)
+	return &call
+}
+
+func (r *Recorder) endCall(call *Call, results []string, panicValue string, isGoexited bool) {
+	endTime := time.Now()
+	r.mutex.Lock()
+	call.Results = results
+	call.PanicValue = panicValue
+	call.IsGoexited = isGoexited
+	call.EndTime = endTime
+	call.IsCompleted = true
+	r.mutex.Unlock()
+}
@@ -0,0 +1,124 @@
+package record_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roy2220/proxyz/internal/testproxy"
+	"github.com/roy2220/proxyz/record"
+)
+
+func TestRecorder(t *testing.T) {
+	r := new(record.Recorder).Init()
+	kv := testproxy.NewKVProxy(testproxy.MapKV{"greeting": "hello"})
+	r.Record(kv)
+	greeter := testproxy.NewGreeterProxy(testproxy.KVGreeter{KV: kv})
+	r.Record(greeter)
+	greeting, err := greeter.Greet(context.Background(), "roy")
+	require.NoError(t, err)
+	assert.Equal(t, "hello, roy", greeting)
+	kv.Keys()
+
+	calls := r.Calls()
+	require.Len(t, calls, 3)
+	assert.Equal(t, "Greet", calls[0].MethodName)
+	assert.Equal(t, uint64(0), calls[0].ParentCallID)
+	assert.Equal(t, []string{"<context>", "roy"}, calls[0].Args)
+	assert.Equal(t, []string{"ctx", "name"}, calls[0].ArgNames)
+	assert.Equal(t, 0, calls[0].ContextArgIndex)
+	assert.Equal(t, []string{"hello, roy", "<nil>"}, calls[0].Results)
+	assert.True(t, calls[0].IsCompleted)
+	assert.Equal(t, "Get", calls[1].MethodName)
+	assert.Equal(t, calls[0].CallID, calls[1].ParentCallID)
+	assert.Equal(t, []string{"<context>", "greeting"}, calls[1].Args)
+	assert.Equal(t, "Keys", calls[2].MethodName)
+	assert.Equal(t, uint64(0), calls[2].ParentCallID)
+
+	var buffer bytes.Buffer
+	err = record.WriteMermaid(&buffer, calls, record.DiagramOptions{ShowArgs: true, ShowResults: true})
+	require.NoError(t, err)
+	assert.Equal(t, `sequenceDiagram
+    participant P0 as Caller
+    participant P1 as testproxy.Greeter
+    participant P2 as testproxy.KV
+    P0->>+P1: Greet(roy)
+    P1->>+P2: Get(greeting)
+    P2-->>-P1: hello, <nil>
+    P1-->>-P0: hello, roy, <nil>
+    P0->>+P2: Keys()
+    P2-->>-P0: [greeting]
+`, buffer.String())
+
+	buffer.Reset()
+	err = record.WritePlantUML(&buffer, calls, record.DiagramOptions{CallerName: "Test"})
+	require.NoError(t, err)
+	assert.Equal(t, `@startuml
+participant "Test" as P0
+participant "testproxy.Greeter" as P1
+participant "testproxy.KV" as P2
+P0 -> P1: Greet
+activate P1
+P1 -> P2: Get
+activate P2
+P2 --> P1
+deactivate P2
+P1 --> P0
+deactivate P1
+P0 -> P2: Keys
+activate P2
+P2 --> P0
+deactivate P2
+@enduml
+`, buffer.String())
+
+	buffer.Reset()
+	err = record.WriteChromeTrace(&buffer, calls)
+	require.NoError(t, err)
+	var trace struct {
+		TraceEvents []struct {
+			Name string            `json:"name"`
+			Cat  string            `json:"cat"`
+			Ph   string            `json:"ph"`
+			Ts   float64           `json:"ts"`
+			Dur  float64           `json:"dur"`
+			Tid  int64             `json:"tid"`
+			Args map[string]string `json:"args"`
+		} `json:"traceEvents"`
+	}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &trace))
+	require.Len(t, trace.TraceEvents, 3)
+	event := trace.TraceEvents[1]
+	assert.Equal(t, "testproxy.KV.Get", event.Name)
+	assert.Equal(t, "github.com/roy2220/proxyz/internal/testproxy.KV", event.Cat)
+	assert.Equal(t, "X", event.Ph)
+	assert.Equal(t, calls[1].GoroutineID, event.Tid)
+	assert.Equal(t, "greeting", event.Args["arg1.key"])
+	assert.NotContains(t, event.Args, "arg0.ctx")
+	assert.Equal(t, "hello", event.Args["result0"])
+	assert.Equal(t, strconv.FormatUint(calls[0].CallID, 10), event.Args["parentCallID"])
+	assert.True(t, event.Ts >= trace.TraceEvents[0].Ts)
+	assert.True(t, event.Ts+event.Dur <= trace.TraceEvents[0].Ts+trace.TraceEvents[0].Dur)
+
+	r.Reset()
+	assert.Empty(t, r.Calls())
+}
+
+func TestRecorderPanic(t *testing.T) {
+	r := new(record.Recorder).Init()
+	kv := testproxy.NewKVProxy(testproxy.MapKV(nil))
+	r.Record(kv)
+	assert.Panics(t, func() {
+		kv.Set(context.Background(), "foo", "bar")
+	})
+	calls := r.Calls()
+	require.Len(t, calls, 1)
+	assert.True(t, calls[0].IsCompleted)
+	assert.Contains(t, calls[0].PanicValue, "nil map")
+	assert.Nil(t, calls[0].Results)
+}
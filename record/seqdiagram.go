@@ -0,0 +1,199 @@
+package record
+
+import (
+	"bufio"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// DiagramOptions represents options for sequence diagrams.
+type DiagramOptions struct {
+	// CallerName is the name of the participant making the top-level method
+	// calls. If not specified, "Caller" will be used.
+	CallerName string
+
+	// ShowArgs indicates whether to show the arguments in the messages.
+	ShowArgs bool
+
+	// ShowResults indicates whether to show the results in the messages.
+	ShowResults bool
+}
+
+// WriteMermaid writes the given method calls, as returned by Recorder.Calls,
+// to the given writer as a sequence diagram in Mermaid.
+func WriteMermaid(writer io.Writer, calls []Call, options DiagramOptions) error {
+	return writeSequenceDiagram(writer, calls, options, mermaidSyntax{})
+}
+
+// WritePlantUML writes the given method calls, as returned by Recorder.Calls,
+// to the given writer as a sequence diagram in PlantUML.
+func WritePlantUML(writer io.Writer, calls []Call, options DiagramOptions) error {
+	return writeSequenceDiagram(writer, calls, options, plantUMLSyntax{})
+}
+
+type diagramSyntax interface {
+	Begin(w *bufio.Writer)
+	Participant(w *bufio.Writer, id string, name string)
+	Call(w *bufio.Writer, fromID string, toID string, text string)
+	Return(w *bufio.Writer, fromID string, toID string, text string)
+	End(w *bufio.Writer)
+}
+
+func writeSequenceDiagram(writer io.Writer, calls []Call, options DiagramOptions, syntax diagramSyntax) error {
+	if options.CallerName == "" {
+		options.CallerName = "Caller"
+	}
+
+	w := bufio.NewWriter(writer)
+	syntax.Begin(w)
+	syntax.Participant(w, "P0", options.CallerName)
+	underlyingType2ParticipantID := make(map[string]string)
+	callID2Call := make(map[uint64]*Call, len(calls))
+	parentCallID2Children := make(map[uint64][]*Call)
+	var rootCalls []*Call
+
+	for i := range calls {
+		callID2Call[calls[i].CallID] = &calls[i]
+	}
+
+	for i := range calls {
+		call := &calls[i]
+
+		if _, ok := underlyingType2ParticipantID[call.UnderlyingType]; !ok {
+			participantID := "P" + strconv.Itoa(len(underlyingType2ParticipantID)+1)
+			underlyingType2ParticipantID[call.UnderlyingType] = participantID
+			syntax.Participant(w, participantID, shortenType(call.UnderlyingType))
+		}
+
+		if _, ok := callID2Call[call.ParentCallID]; ok {
+			parentCallID2Children[call.ParentCallID] = append(parentCallID2Children[call.ParentCallID], call)
+		} else {
+			rootCalls = append(rootCalls, call)
+		}
+	}
+
+	var writeCall func(call *Call, callerID string)
+
+	writeCall = func(call *Call, callerID string) {
+		participantID := underlyingType2ParticipantID[call.UnderlyingType]
+		text := call.MethodName
+
+		if options.ShowArgs {
+			args := make([]string, 0, len(call.Args))
+
+			for i, arg := range call.Args {
+				if i != call.ContextArgIndex {
+					args = append(args, arg)
+				}
+			}
+
+			text += "(" + strings.Join(args, ", ") + ")"
+		}
+
+		syntax.Call(w, callerID, participantID, text)
+
+		for _, childCall := range parentCallID2Children[call.CallID] {
+			writeCall(childCall, participantID)
+		}
+
+		var returnText string
+
+		switch {
+		case !call.IsCompleted:
+			returnText = "(in flight)"
+		case call.PanicValue != "":
+			returnText = "panic: " + call.PanicValue
+		case call.IsGoexited:
+			returnText = "goexit"
+		case options.ShowResults:
+			returnText = strings.Join(call.Results, ", ")
+		}
+
+		syntax.Return(w, participantID, callerID, returnText)
+	}
+
+	for _, rootCall := range rootCalls {
+		writeCall(rootCall, "P0")
+	}
+
+	syntax.End(w)
+	return w.Flush()
+}
+
+type mermaidSyntax struct{}
+
+func (mermaidSyntax) Begin(w *bufio.Writer) {
+	w.WriteString("sequenceDiagram\n")
+}
+
+func (mermaidSyntax) Participant(w *bufio.Writer, id string, name string) {
+	w.WriteString("    participant " + id + " as " + escapeMermaidText(name) + "\n")
+}
+
+func (mermaidSyntax) Call(w *bufio.Writer, fromID string, toID string, text string) {
+	w.WriteString("    " + fromID + "->>+" + toID + ": " + escapeMermaidText(text) + "\n")
+}
+
+func (mermaidSyntax) Return(w *bufio.Writer, fromID string, toID string, text string) {
+	w.WriteString("    " + fromID + "-->>-" + toID + ": " + escapeMermaidText(text) + "\n")
+}
+
+func (mermaidSyntax) End(*bufio.Writer) {}
+
+var mermaidTextEscaper = strings.NewReplacer(
+	"#", "#35;",
+	";", "#59;",
+	"\n", " ",
+)
+
+func escapeMermaidText(text string) string {
+	return mermaidTextEscaper.Replace(text)
+}
+
+type plantUMLSyntax struct{}
+
+func (plantUMLSyntax) Begin(w *bufio.Writer) {
+	w.WriteString("@startuml\n")
+}
+
+func (plantUMLSyntax) Participant(w *bufio.Writer, id string, name string) {
+	w.WriteString("participant " + strconv.Quote(name) + " as " + id + "\n")
+}
+
+func (plantUMLSyntax) Call(w *bufio.Writer, fromID string, toID string, text string) {
+	w.WriteString(fromID + " -> " + toID + ": " + escapePlantUMLText(text) + "\n")
+	w.WriteString("activate " + toID + "\n")
+}
+
+func (plantUMLSyntax) Return(w *bufio.Writer, fromID string, toID string, text string) {
+	w.WriteString(fromID + " --> " + toID)
+
+	if text != "" {
+		w.WriteString(": " + escapePlantUMLText(text))
+	}
+
+	w.WriteString("\n")
+	w.WriteString("deactivate " + fromID + "\n")
+}
+
+func (plantUMLSyntax) End(w *bufio.Writer) {
+	w.WriteString("@enduml\n")
+}
+
+var plantUMLTextEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"\n", "\\n",
+)
+
+func escapePlantUMLText(text string) string {
+	return plantUMLTextEscaper.Replace(text)
+}
+
+// shortenType shortens the representation of the given underlying type by
+// removing the directories of the package path, e.g. "net/http.Client" to
+// "http.Client".
+func shortenType(underlyingType string) string {
+	return path.Base(underlyingType)
+}
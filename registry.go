@@ -0,0 +1,79 @@
+package proxyz
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RegisteredProxy represents a proxy registered in the process-wide registry.
+type RegisteredProxy struct {
+	Name  string
+	Proxy Proxy
+}
+
+// RegisterProxy registers the given proxy in the process-wide registry under
+// the given instance name, which should be non-empty, unique and free of "/"
+// for use in URL paths. Registered proxies are exposed for inspection and
+// runtime control, e.g. by package admin, until unregistered.
+func RegisterProxy(name string, proxy Proxy) error {
+	if name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("proxyz: invalid proxy name; name=%q", name)
+	}
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if _, ok := name2RegisteredProxy[name]; ok {
+		return fmt.Errorf("proxyz: duplicate proxy name; name=%q", name)
+	}
+
+	name2RegisteredProxy[name] = proxy
+	return nil
+}
+
+// UnregisterProxy unregisters the proxy with the given instance name from the
+// process-wide registry. It returns false if no such proxy is found.
+func UnregisterProxy(name string) bool {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if _, ok := name2RegisteredProxy[name]; !ok {
+		return false
+	}
+
+	delete(name2RegisteredProxy, name)
+	return true
+}
+
+// LookupProxy returns the proxy registered under the given instance name.
+func LookupProxy(name string) (Proxy, bool) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	proxy, ok := name2RegisteredProxy[name]
+	return proxy, ok
+}
+
+// ListProxies returns the proxies registered, ordered by the instance names.
+func ListProxies() []RegisteredProxy {
+	registryMutex.Lock()
+	registeredProxies := make([]RegisteredProxy, 0, len(name2RegisteredProxy))
+
+	for name, proxy := range name2RegisteredProxy {
+		registeredProxies = append(registeredProxies, RegisteredProxy{name, proxy})
+	}
+
+	registryMutex.Unlock()
+
+	sort.Slice(registeredProxies, func(i, j int) bool {
+		return registeredProxies[i].Name < registeredProxies[j].Name
+	})
+
+	return registeredProxies
+}
+
+var (
+	registryMutex        sync.Mutex
+	name2RegisteredProxy = make(map[string]Proxy)
+)
@@ -0,0 +1,283 @@
+// Package shadow provides an interceptor sending copies of method calls to a
+// shadow implementation and comparing the results with the primary ones.
+package shadow
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/roy2220/proxyz"
+)
+
+// Comparator is the type of function deciding whether the results of a call
+// to the method with the given name, from the primary and the shadow
+// implementations, are equivalent.
+type Comparator func(methodName string, primaryResults []interface{}, shadowResults []interface{}) bool
+
+// Divergence represents a divergence between the primary and the shadow
+// implementations.
+type Divergence struct {
+	MethodName string
+
+	// Args are the representations of the arguments, with the sensitive ones
+	// redacted (see proxyz.FormatArgs).
+	Args []string
+
+	PrimaryResults []interface{}
+	ShadowResults  []interface{}
+
+	// ShadowPanicValue is the value the shadow implementation panicked with,
+	// if any, in which case ShadowResults is nil.
+	ShadowPanicValue interface{}
+}
+
+// Options represents options for shadowers.
+type Options struct {
+	// SampleRate is the fraction, from 0 to 1, of the method calls to send to
+	// the shadow implementation.
+	SampleRate float64
+
+	// Seed is the seed of the random number generator for sampling.
+	Seed int64
+
+	// MaxConcurrency is the maximum number of in-flight calls to the shadow
+	// implementation, the method calls beyond the limit will not be sent to
+	// the shadow implementation. Zero means no limit.
+	MaxConcurrency int
+
+	// Comparator compares the results. If not specified, DefaultComparator
+	// will be used.
+	Comparator Comparator
+
+	// DivergenceHandler handles the divergences found. It is called from
+	// the goroutines calling the shadow implementation.
+	DivergenceHandler func(divergence *Divergence)
+}
+
+// Shadower sends copies of method calls to a shadow implementation.
+//
+// The copies share the arguments with the original method calls, so the
+// arguments of reference types, e.g. slices, maps and pointers, must not be
+// mutated by either implementation, which would be a data race otherwise.
+type Shadower struct {
+	shadow    reflect.Value
+	options   Options
+	randMutex sync.Mutex
+	rand      *rand.Rand
+	semaphore chan struct{}
+	waitGroup sync.WaitGroup
+}
+
+// Init initializes the shadower with the given shadow implementation, which
+// should implement the same methods as the primary one, and the given options
+// and returns it.
+func (s *Shadower) Init(shadow interface{}, options Options) *Shadower {
+	if options.Comparator == nil {
+		options.Comparator = DefaultComparator
+	}
+
+	s.shadow = reflect.ValueOf(shadow)
+	s.options = options
+	s.rand = rand.New(rand.NewSource(options.Seed))
+
+	if options.MaxConcurrency >= 1 {
+		s.semaphore = make(chan struct{}, options.MaxConcurrency)
+	}
+
+	return s
+}
+
+// InterceptMethodCall is a proxyz.MethodCallInterceptor forwarding the
+// method call and sending a copy of the method call to the shadow
+// implementation asynchronously, if sampled. Only the primary results are
+// returned.
+func (s *Shadower) InterceptMethodCall(methodCall proxyz.MethodCall) {
+	if !s.sample() {
+		methodCall.Forward()
+		return
+	}
+
+	method := s.shadow.MethodByName(methodCall.MethodName())
+
+	if !method.IsValid() || !s.acquire() {
+		methodCall.Forward()
+		return
+	}
+
+	args := make([]interface{}, methodCall.NumberOfArgs())
+
+	for i := range args {
+		args[i] = methodCall.GetArg(i)
+	}
+
+	if i := methodCall.ContextArgIndex(); i >= 0 && args[i] != nil {
+		args[i] = detachedContext{args[i].(context.Context)}
+	}
+
+	primaryResultsCh := make(chan []interface{}, 1)
+	s.waitGroup.Add(1)
+	go s.callShadow(methodCall.MethodName(), method, args, proxyz.FormatArgs(methodCall), primaryResultsCh)
+	defer close(primaryResultsCh)
+	methodCall.Forward()
+	primaryResults := make([]interface{}, methodCall.NumberOfResults())
+
+	for i := range primaryResults {
+		primaryResults[i] = methodCall.GetResult(i)
+	}
+
+	primaryResultsCh <- primaryResults
+}
+
+// Wait waits for the in-flight calls to the shadow implementation to complete.
+func (s *Shadower) Wait() {
+	s.waitGroup.Wait()
+}
+
+func (s *Shadower) sample() bool {
+	if s.options.SampleRate <= 0 {
+		return false
+	}
+
+	s.randMutex.Lock()
+	x := s.rand.Float64()
+	s.randMutex.Unlock()
+	return x < s.options.SampleRate
+}
+
+func (s *Shadower) acquire() bool {
+	if s.semaphore == nil {
+		return true
+	}
+
+	select {
+	case s.semaphore <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Shadower) release() {
+	if s.semaphore != nil {
+		<-s.semaphore
+	}
+}
+
+func (s *Shadower) callShadow(
+	methodName string,
+	method reflect.Value,
+	args []interface{},
+	formattedArgs []string,
+	primaryResultsCh <-chan []interface{},
+) {
+	defer s.waitGroup.Done()
+	defer s.release()
+	shadowResults, shadowPanicValue := callMethod(method, args)
+	primaryResults, ok := <-primaryResultsCh
+
+	if !ok {
+		// the primary implementation panicked
+		return
+	}
+
+	if shadowPanicValue == nil && s.options.Comparator(methodName, primaryResults, shadowResults) {
+		return
+	}
+
+	if s.options.DivergenceHandler == nil {
+		return
+	}
+
+	s.options.DivergenceHandler(&Divergence{
+		MethodName:       methodName,
+		Args:             formattedArgs,
+		PrimaryResults:   primaryResults,
+		ShadowResults:    shadowResults,
+		ShadowPanicValue: shadowPanicValue,
+	})
+}
+
+func callMethod(method reflect.Value, args []interface{}) (results []interface{}, panicValue interface{}) {
+	defer func() {
+		if panicValue2 := recover(); panicValue2 != nil {
+			results, panicValue = nil, panicValue2
+		}
+	}()
+
+	methodType := method.Type()
+
+	if methodType.NumIn() != len(args) {
+		panic(fmt.Sprintf("shadow: number of arguments mismatch; expected=%d actual=%d", methodType.NumIn(), len(args)))
+	}
+
+	argValues := make([]reflect.Value, len(args))
+
+	for i, arg := range args {
+		if arg == nil {
+			argValues[i] = reflect.Zero(methodType.In(i))
+		} else {
+			argValues[i] = reflect.ValueOf(arg)
+		}
+	}
+
+	var resultValues []reflect.Value
+
+	if methodType.IsVariadic() {
+		resultValues = method.CallSlice(argValues)
+	} else {
+		resultValues = method.Call(argValues)
+	}
+
+	results = make([]interface{}, len(resultValues))
+
+	for i, resultValue := range resultValues {
+		results[i] = resultValue.Interface()
+	}
+
+	return results, nil
+}
+
+// DefaultComparator is the default Comparator, which compares the results
+// with reflect.DeepEqual, except errors, which are compared by their
+// messages.
+func DefaultComparator(_ string, primaryResults []interface{}, shadowResults []interface{}) bool {
+	if len(primaryResults) != len(shadowResults) {
+		return false
+	}
+
+	for i, primaryResult := range primaryResults {
+		shadowResult := shadowResults[i]
+		primaryErr, ok1 := primaryResult.(error)
+		shadowErr, ok2 := shadowResult.(error)
+
+		if ok1 && ok2 {
+			if primaryErr.Error() != shadowErr.Error() {
+				return false
+			}
+
+			continue
+		}
+
+		if !reflect.DeepEqual(primaryResult, shadowResult) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// detachedContext is a context keeping the values of the parent context but
+// never being canceled, for the calls to the shadow implementation outliving
+// the method calls.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool)          { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}                { return nil }
+func (detachedContext) Err() error                           { return nil }
+func (dc detachedContext) Value(key interface{}) interface{} { return dc.parent.Value(key) }
@@ -0,0 +1,78 @@
+package shadow_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roy2220/proxyz"
+	"github.com/roy2220/proxyz/internal/testproxy"
+	"github.com/roy2220/proxyz/shadow"
+)
+
+func TestShadower(t *testing.T) {
+	var mutex sync.Mutex
+	var divergences []*shadow.Divergence
+	shadower := new(shadow.Shadower).Init(testproxy.MapKV{"foo": "bar", "baz": "qux2"}, shadow.Options{
+		SampleRate: 1,
+		DivergenceHandler: func(divergence *shadow.Divergence) {
+			mutex.Lock()
+			divergences = append(divergences, divergence)
+			mutex.Unlock()
+		},
+	})
+	kv := testproxy.NewKVProxy(testproxy.MapKV{"foo": "bar", "baz": "qux"})
+	proxyz.InterceptAllMethodCalls(kv, shadower.InterceptMethodCall)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	v, err := kv.Get(ctx, "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", v)
+	v, err = kv.Get(ctx, "baz")
+	assert.NoError(t, err)
+	assert.Equal(t, "qux", v)
+	_, err = kv.Get(ctx, "none")
+	assert.Equal(t, testproxy.ErrNotFound, err)
+	cancel()
+	shadower.Wait()
+
+	require.Len(t, divergences, 1)
+	assert.Equal(t, "Get", divergences[0].MethodName)
+	assert.Equal(t, "baz", divergences[0].Args[1])
+	assert.Equal(t, []interface{}{"qux", nil}, divergences[0].PrimaryResults)
+	assert.Equal(t, []interface{}{"qux2", nil}, divergences[0].ShadowResults)
+}
+
+func TestShadowerSampling(t *testing.T) {
+	var mutex sync.Mutex
+	n := 0
+	shadower := new(shadow.Shadower).Init(testproxy.MapKV{}, shadow.Options{
+		SampleRate: 0.5,
+		Seed:       1,
+		Comparator: func(methodName string, primaryResults []interface{}, shadowResults []interface{}) bool {
+			mutex.Lock()
+			n++
+			mutex.Unlock()
+			return true
+		},
+	})
+	kv := testproxy.NewKVProxy(testproxy.MapKV{})
+	proxyz.InterceptAllMethodCalls(kv, shadower.InterceptMethodCall)
+
+	for i := 0; i < 100; i++ {
+		kv.Keys()
+	}
+
+	shadower.Wait()
+	assert.True(t, n >= 25 && n <= 75, "n=%d", n)
+}
+
+func TestDefaultComparator(t *testing.T) {
+	assert.True(t, shadow.DefaultComparator("", []interface{}{1, context.Canceled}, []interface{}{1, context.Canceled}))
+	assert.False(t, shadow.DefaultComparator("", []interface{}{1, nil}, []interface{}{1, context.Canceled}))
+	assert.False(t, shadow.DefaultComparator("", []interface{}{[]int{1}}, []interface{}{[]int{2}}))
+	assert.False(t, shadow.DefaultComparator("", []interface{}{1}, nil))
+}
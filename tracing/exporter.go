@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// MemoryExporter is an Exporter keeping the spans in memory.
+type MemoryExporter struct {
+	mutex sync.Mutex
+	spans []Span
+}
+
+var _ = (Exporter)((*MemoryExporter)(nil))
+
+// ExportSpan implements Exporter.ExportSpan.
+func (me *MemoryExporter) ExportSpan(span *Span) {
+	me.mutex.Lock()
+	me.spans = append(me.spans, *span)
+	me.mutex.Unlock()
+}
+
+// Spans returns a copy of the spans exported, in the order of export.
+func (me *MemoryExporter) Spans() []Span {
+	me.mutex.Lock()
+	defer me.mutex.Unlock()
+	return append([]Span(nil), me.spans...)
+}
+
+// Reset discards the spans exported.
+func (me *MemoryExporter) Reset() {
+	me.mutex.Lock()
+	me.spans = nil
+	me.mutex.Unlock()
+}
+
+// FileExporter is an Exporter writing the spans to a file in the format of
+// JSON lines.
+type FileExporter struct {
+	mutex   sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+	err     error
+}
+
+var _ = (Exporter)((*FileExporter)(nil))
+
+// Open opens the file with the given path for appending spans, the file
+// will be created if it does not exist.
+func (fe *FileExporter) Open(filePath string) error {
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+
+	if err != nil {
+		return fmt.Errorf("tracing: file open failed; filePath=%q: %v", filePath, err)
+	}
+
+	fe.file = file
+	fe.encoder = json.NewEncoder(file)
+	return nil
+}
+
+// ExportSpan implements Exporter.ExportSpan.
+func (fe *FileExporter) ExportSpan(span *Span) {
+	fe.mutex.Lock()
+	defer fe.mutex.Unlock()
+
+	if fe.err != nil {
+		return
+	}
+
+	if err := fe.encoder.Encode(span); err != nil {
+		fe.err = fmt.Errorf("tracing: span write failed; filePath=%q: %v", fe.file.Name(), err)
+	}
+}
+
+// Close closes the file. It returns the first error encountered while
+// writing spans, if any.
+func (fe *FileExporter) Close() error {
+	fe.mutex.Lock()
+	defer fe.mutex.Unlock()
+	err := fe.err
+
+	if err2 := fe.file.Close(); err == nil && err2 != nil {
+		err = fmt.Errorf("tracing: file close failed; filePath=%q: %v", fe.file.Name(), err2)
+	}
+
+	return err
+}
@@ -0,0 +1,176 @@
+// Package tracing provides an interceptor tracing method calls as spans.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/roy2220/proxyz"
+)
+
+// Span represents a traced method call.
+type Span struct {
+	TraceID      ID                `json:"trace_id"`
+	SpanID       ID                `json:"span_id"`
+	ParentSpanID ID                `json:"parent_span_id,omitempty"`
+	Name         string            `json:"name"`
+	StartTime    time.Time         `json:"start_time"`
+	EndTime      time.Time         `json:"end_time"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+}
+
+// Duration returns the duration of the span.
+func (s *Span) Duration() time.Duration {
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// ID represents an identifier of a trace or a span.
+type ID uint64
+
+// String returns the hexadecimal representation of the id.
+func (id ID) String() string {
+	return fmt.Sprintf("%016x", uint64(id))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (id ID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (id *ID) UnmarshalText(text []byte) error {
+	x, err := strconv.ParseUint(string(text), 16, 64)
+
+	if err != nil {
+		return fmt.Errorf("tracing: invalid id; text=%q: %v", text, err)
+	}
+
+	*id = ID(x)
+	return nil
+}
+
+// SpanContext represents the identity of a span propagated through contexts.
+type SpanContext struct {
+	TraceID ID
+	SpanID  ID
+}
+
+// ContextWithSpanContext returns a copy of the given context carrying the
+// given span context.
+func ContextWithSpanContext(ctx context.Context, spanContext SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, spanContext)
+}
+
+// SpanContextFromContext returns the span context carried by the given
+// context, if any.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	if ctx == nil {
+		return SpanContext{}, false
+	}
+
+	spanContext, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return spanContext, ok
+}
+
+type spanContextKey struct{}
+
+// Exporter is the interface for exporting the spans ended.
+type Exporter interface {
+	// ExportSpan exports the given span. It may be called concurrently.
+	ExportSpan(span *Span)
+}
+
+// Tracer traces method calls through proxies.
+type Tracer struct {
+	exporter Exporter
+}
+
+// Init initializes the tracer with the given exporter and returns it.
+func (t *Tracer) Init(exporter Exporter) *Tracer {
+	t.exporter = exporter
+	return t
+}
+
+// Trace adds an interceptor to the given proxy to trace the calls to all
+// the methods of the proxy.
+func (t *Tracer) Trace(proxy proxyz.Proxy) {
+	proxyz.InterceptAllMethodCalls(proxy, t.NewMethodCallInterceptor(proxy.XxxUnderlyingType()))
+}
+
+// NewMethodCallInterceptor returns an interceptor tracing the method calls,
+// the spans of which are named after the given underlying type and the
+// methods.
+func (t *Tracer) NewMethodCallInterceptor(underlyingType string) proxyz.MethodCallInterceptor {
+	return func(methodCall proxyz.MethodCall) {
+		span := Span{
+			Name:      underlyingType + "." + methodCall.MethodName(),
+			SpanID:    newID(),
+			StartTime: time.Now(),
+		}
+
+		ctx, ok := proxyz.GetContext(methodCall)
+
+		if parentSpanContext, ok := SpanContextFromContext(ctx); ok {
+			span.TraceID = parentSpanContext.TraceID
+			span.ParentSpanID = parentSpanContext.SpanID
+		} else {
+			span.TraceID = newID()
+		}
+
+		if ok {
+			methodCall.SetArg(methodCall.ContextArgIndex(), ContextWithSpanContext(ctx, SpanContext{
+				TraceID: span.TraceID,
+				SpanID:  span.SpanID,
+			}))
+		}
+
+		span.Attributes = make(map[string]string)
+		setArgAttributes(span.Attributes, methodCall)
+		proxyz.ForwardAndObserve(methodCall, func(outcome proxyz.Outcome, panicValue interface{}) {
+			span.EndTime = time.Now()
+
+			switch outcome {
+			case proxyz.Returned:
+				if err := proxyz.GetError(methodCall); err != nil {
+					span.Attributes["error"] = err.Error()
+				}
+			case proxyz.Panicked:
+				span.Attributes["panic"] = fmt.Sprint(panicValue)
+			default:
+				span.Attributes["goexit"] = "true"
+			}
+
+			t.exporter.ExportSpan(&span)
+		})
+	}
+}
+
+func setArgAttributes(attributes map[string]string, methodCall proxyz.MethodCall) {
+	for i, n := 0, methodCall.NumberOfArgs(); i < n; i++ {
+		if i == methodCall.ContextArgIndex() {
+			continue
+		}
+
+		attributes["arg"+strconv.Itoa(i)] = proxyz.FormatArg(methodCall, i)
+	}
+}
+
+var (
+	idRandMutex sync.Mutex
+	idRand      = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+func newID() ID {
+	idRandMutex.Lock()
+	defer idRandMutex.Unlock()
+
+	for {
+		if id := ID(idRand.Uint64()); id != 0 {
+			return id
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package tracing_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roy2220/proxyz/internal/testproxy"
+	"github.com/roy2220/proxyz/tracing"
+)
+
+func TestTracer(t *testing.T) {
+	var exporter tracing.MemoryExporter
+	tracer := new(tracing.Tracer).Init(&exporter)
+	kv := testproxy.NewKVProxy(testproxy.MapKV{"greeting": "hello"})
+	tracer.Trace(kv)
+	greeter := testproxy.NewGreeterProxy(testproxy.KVGreeter{KV: kv})
+	tracer.Trace(greeter)
+
+	s, err := greeter.Greet(context.Background(), "roy")
+	require.NoError(t, err)
+	assert.Equal(t, "hello, roy", s)
+	_, err = kv.Get(context.Background(), "foo")
+	assert.Equal(t, testproxy.ErrNotFound, err)
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 3)
+	kvGetSpan, greeterGreetSpan, kvGetSpan2 := spans[0], spans[1], spans[2]
+	assert.Equal(t, "github.com/roy2220/proxyz/internal/testproxy.KV.Get", kvGetSpan.Name)
+	assert.Equal(t, "github.com/roy2220/proxyz/internal/testproxy.Greeter.Greet", greeterGreetSpan.Name)
+	assert.Equal(t, greeterGreetSpan.TraceID, kvGetSpan.TraceID)
+	assert.Equal(t, greeterGreetSpan.SpanID, kvGetSpan.ParentSpanID)
+	assert.Zero(t, greeterGreetSpan.ParentSpanID)
+	assert.Equal(t, map[string]string{"arg1": "greeting"}, kvGetSpan.Attributes)
+	assert.Equal(t, map[string]string{"arg1": "roy"}, greeterGreetSpan.Attributes)
+	assert.NotEqual(t, greeterGreetSpan.TraceID, kvGetSpan2.TraceID)
+	assert.Equal(t, map[string]string{"arg1": "foo", "error": testproxy.ErrNotFound.Error()}, kvGetSpan2.Attributes)
+
+	exporter.Reset()
+	assert.Empty(t, exporter.Spans())
+}
+
+func TestFileExporter(t *testing.T) {
+	dirPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dirPath)
+	filePath := filepath.Join(dirPath, "spans.jsonl")
+	var exporter tracing.FileExporter
+	require.NoError(t, exporter.Open(filePath))
+	tracer := new(tracing.Tracer).Init(&exporter)
+	kv := testproxy.NewKVProxy(testproxy.MapKV{})
+	tracer.Trace(kv)
+	assert.NoError(t, kv.Set(context.Background(), "foo", "bar"))
+	assert.Equal(t, []string{"foo"}, kv.Keys())
+	require.NoError(t, exporter.Close())
+
+	file, err := os.Open(filePath)
+	require.NoError(t, err)
+	defer file.Close()
+	var spans []tracing.Span
+
+	for scanner := bufio.NewScanner(file); scanner.Scan(); {
+		var span tracing.Span
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &span))
+		spans = append(spans, span)
+	}
+
+	require.Len(t, spans, 2)
+	assert.Equal(t, "github.com/roy2220/proxyz/internal/testproxy.KV.Set", spans[0].Name)
+	assert.Equal(t, map[string]string{"arg1": "foo", "arg2": "bar"}, spans[0].Attributes)
+	assert.Equal(t, "github.com/roy2220/proxyz/internal/testproxy.KV.Keys", spans[1].Name)
+	assert.NotZero(t, spans[1].SpanID)
+	assert.True(t, spans[1].Duration() >= 0)
+}
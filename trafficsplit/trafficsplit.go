@@ -0,0 +1,262 @@
+// Package trafficsplit provides an interceptor routing a fraction of method
+// calls to an alternate implementation, e.g. for canary releases.
+package trafficsplit
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+
+	"github.com/roy2220/proxyz"
+)
+
+// StickyKeyGetter is the type of function getting the key of a method call
+// for sticky routing. Method calls with the same key are routed to the same
+// implementation, as long as the fraction is unchanged. It returns false if
+// the method call has no key, in which case the method call is routed
+// randomly.
+type StickyKeyGetter func(methodCall proxyz.MethodCall) (string, bool)
+
+// ArgStickyKey returns a StickyKeyGetter taking the argument at the given
+// index, formatted by fmt.Sprint, as the key.
+func ArgStickyKey(argIndex int) StickyKeyGetter {
+	return func(methodCall proxyz.MethodCall) (string, bool) {
+		if argIndex >= methodCall.NumberOfArgs() {
+			return "", false
+		}
+
+		return fmt.Sprint(methodCall.GetArg(argIndex)), true
+	}
+}
+
+// ContextStickyKey returns a StickyKeyGetter taking the value for the given
+// key in the context.Context argument, formatted by fmt.Sprint, as the key.
+func ContextStickyKey(contextKey interface{}) StickyKeyGetter {
+	return func(methodCall proxyz.MethodCall) (string, bool) {
+		ctx, ok := proxyz.GetContext(methodCall)
+
+		if !ok {
+			return "", false
+		}
+
+		value := ctx.Value(contextKey)
+
+		if value == nil {
+			return "", false
+		}
+
+		return fmt.Sprint(value), true
+	}
+}
+
+// Options represents options for splitters.
+type Options struct {
+	// Fraction is the initial fraction, from 0 to 1, of the method calls to
+	// route to the alternate implementation.
+	Fraction float64
+
+	// MethodFractions are the initial fractions per method, overriding
+	// Fraction.
+	MethodFractions map[string]float64
+
+	// StickyKeyGetter gets the key for sticky routing. If not specified,
+	// method calls are routed randomly.
+	StickyKeyGetter StickyKeyGetter
+
+	// Seed is the seed of the random number generator for random routing.
+	Seed int64
+}
+
+// Target represents the implementation which a method call is routed to.
+type Target int
+
+const (
+	// Primary is the primary implementation, i.e. the underlying object of
+	// the proxy.
+	Primary Target = iota
+
+	// Alternate is the alternate implementation.
+	Alternate
+)
+
+// String returns the representation of the target.
+func (t Target) String() string {
+	switch t {
+	case Primary:
+		return "primary"
+	case Alternate:
+		return "alternate"
+	default:
+		return fmt.Sprintf("Target(%d)", int(t))
+	}
+}
+
+// Outcomes represents the outcome counters of the method calls routed to a
+// target. The method calls during which the goroutines exited through
+// runtime.Goexit are counted in Calls only.
+type Outcomes struct {
+	Calls  uint64
+	Errors uint64
+	Panics uint64
+}
+
+// Splitter routes a fraction of method calls to an alternate implementation.
+type Splitter struct {
+	alternate       interface{}
+	stickyKeyGetter StickyKeyGetter
+
+	mutex           sync.RWMutex
+	fraction        float64
+	methodFractions map[string]float64
+
+	randMutex sync.Mutex
+	rand      *rand.Rand
+
+	outcomes [2]Outcomes
+}
+
+// Init initializes the splitter, for the method calls of the given proxy, with
+// the given alternate implementation, which must be of the underlying type of
+// the proxy, and the given options and returns it.
+func (s *Splitter) Init(proxy proxyz.Proxy, alternate interface{}, options Options) *Splitter {
+	if !proxy.XxxIsOfUnderlyingType(alternate) {
+		panic(fmt.Errorf("trafficsplit: alternate implementation of wrong type; underlyingType=%q alternateType=%T",
+			proxy.XxxUnderlyingType(), alternate))
+	}
+
+	s.alternate = alternate
+	s.stickyKeyGetter = options.StickyKeyGetter
+	s.fraction = options.Fraction
+	s.methodFractions = make(map[string]float64, len(options.MethodFractions))
+
+	for methodName, fraction := range options.MethodFractions {
+		s.methodFractions[methodName] = fraction
+	}
+
+	s.rand = rand.New(rand.NewSource(options.Seed))
+	return s
+}
+
+// SetFraction sets the fraction of the method calls to route to the alternate
+// implementation.
+func (s *Splitter) SetFraction(fraction float64) {
+	s.mutex.Lock()
+	s.fraction = fraction
+	s.mutex.Unlock()
+}
+
+// SetMethodFraction sets the fraction of the calls to the method with the
+// given name to route to the alternate implementation, overriding the fraction
+// set by SetFraction.
+func (s *Splitter) SetMethodFraction(methodName string, fraction float64) {
+	s.mutex.Lock()
+	s.methodFractions[methodName] = fraction
+	s.mutex.Unlock()
+}
+
+// ClearMethodFraction clears the fraction set by SetMethodFraction for the
+// method with the given name.
+func (s *Splitter) ClearMethodFraction(methodName string) {
+	s.mutex.Lock()
+	delete(s.methodFractions, methodName)
+	s.mutex.Unlock()
+}
+
+// Outcomes returns the outcome counters of the method calls routed to the
+// given target.
+func (s *Splitter) Outcomes(target Target) Outcomes {
+	outcomes := &s.outcomes[target]
+
+	return Outcomes{
+		Calls:  atomic.LoadUint64(&outcomes.Calls),
+		Errors: atomic.LoadUint64(&outcomes.Errors),
+		Panics: atomic.LoadUint64(&outcomes.Panics),
+	}
+}
+
+// InterceptMethodCall is a proxyz.MethodCallInterceptor routing the method
+// call to either the primary or the alternate implementation.
+func (s *Splitter) InterceptMethodCall(methodCall proxyz.MethodCall) {
+	target := s.route(methodCall)
+
+	if target == Alternate {
+		methodCall.SetCallee(s.alternate)
+	}
+
+	outcomes := &s.outcomes[target]
+	atomic.AddUint64(&outcomes.Calls, 1)
+
+	proxyz.ForwardAndObserve(methodCall, func(outcome proxyz.Outcome, _ interface{}) {
+		switch outcome {
+		case proxyz.Returned:
+			if proxyz.GetError(methodCall) != nil {
+				atomic.AddUint64(&outcomes.Errors, 1)
+			}
+		case proxyz.Panicked:
+			atomic.AddUint64(&outcomes.Panics, 1)
+		}
+	})
+}
+
+func (s *Splitter) route(methodCall proxyz.MethodCall) Target {
+	fraction := s.getFraction(methodCall.MethodName())
+
+	if fraction <= 0 {
+		return Primary
+	}
+
+	if fraction >= 1 {
+		return Alternate
+	}
+
+	var x float64
+
+	if key, ok := s.getStickyKey(methodCall); ok {
+		x = float64(hashKey(key)) / (math.MaxUint64 + 1.0)
+	} else {
+		s.randMutex.Lock()
+		x = s.rand.Float64()
+		s.randMutex.Unlock()
+	}
+
+	if x < fraction {
+		return Alternate
+	}
+
+	return Primary
+}
+
+func (s *Splitter) getFraction(methodName string) float64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if fraction, ok := s.methodFractions[methodName]; ok {
+		return fraction
+	}
+
+	return s.fraction
+}
+
+func (s *Splitter) getStickyKey(methodCall proxyz.MethodCall) (string, bool) {
+	if s.stickyKeyGetter == nil {
+		return "", false
+	}
+
+	return s.stickyKeyGetter(methodCall)
+}
+
+func hashKey(key string) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	h := hash.Sum64()
+	// fmix64 of MurmurHash3, for spreading short keys evenly
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
@@ -0,0 +1,116 @@
+package trafficsplit_test
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/roy2220/proxyz"
+	"github.com/roy2220/proxyz/internal/testproxy"
+	"github.com/roy2220/proxyz/trafficsplit"
+)
+
+func TestSplitter(t *testing.T) {
+	primary := testproxy.MapKV{"foo": "primary"}
+	alternate := testproxy.MapKV{"foo": "alternate"}
+	kv := testproxy.NewKVProxy(primary)
+	splitter := new(trafficsplit.Splitter).Init(kv, alternate, trafficsplit.Options{
+		MethodFractions: map[string]float64{"Get": 1},
+	})
+	proxyz.InterceptAllMethodCalls(kv, splitter.InterceptMethodCall)
+	ctx := context.Background()
+
+	v, _ := kv.Get(ctx, "foo")
+	assert.Equal(t, "alternate", v)
+	_, err := kv.Get(ctx, "bar")
+	assert.Equal(t, testproxy.ErrNotFound, err)
+	assert.NoError(t, kv.Set(ctx, "bar", "baz"))
+	assert.Equal(t, "baz", primary["bar"])
+
+	splitter.SetFraction(1)
+	assert.NoError(t, kv.Set(ctx, "qux", "baz"))
+	assert.Equal(t, "baz", alternate["qux"])
+
+	splitter.SetMethodFraction("Get", 0)
+	v, _ = kv.Get(ctx, "foo")
+	assert.Equal(t, "primary", v)
+	splitter.ClearMethodFraction("Get")
+	v, _ = kv.Get(ctx, "foo")
+	assert.Equal(t, "alternate", v)
+
+	assert.Equal(t, trafficsplit.Outcomes{Calls: 2}, splitter.Outcomes(trafficsplit.Primary))
+	assert.Equal(t, trafficsplit.Outcomes{Calls: 4, Errors: 1}, splitter.Outcomes(trafficsplit.Alternate))
+
+	assert.Panics(t, func() {
+		new(trafficsplit.Splitter).Init(kv, testproxy.AliasingSorter{}, trafficsplit.Options{})
+	})
+}
+
+func TestSplitterStickyKey(t *testing.T) {
+	primary := testproxy.MapKV{}
+	alternate := testproxy.MapKV{}
+	kv := testproxy.NewKVProxy(primary)
+	splitter := new(trafficsplit.Splitter).Init(kv, alternate, trafficsplit.Options{
+		Fraction:        0.5,
+		StickyKeyGetter: trafficsplit.ArgStickyKey(1),
+	})
+	proxyz.InterceptAllMethodCalls(kv, splitter.InterceptMethodCall)
+	ctx := context.Background()
+
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		assert.NoError(t, kv.Set(ctx, key, key))
+	}
+
+	assert.True(t, len(primary) >= 25 && len(alternate) >= 25, "%d/%d", len(primary), len(alternate))
+
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		v, err := kv.Get(ctx, key)
+		assert.NoError(t, err)
+		assert.Equal(t, key, v)
+	}
+}
+
+func TestContextStickyKey(t *testing.T) {
+	type userKey struct{}
+	alternate := testproxy.MapKV{}
+	kv := testproxy.NewKVProxy(testproxy.MapKV{})
+	splitter := new(trafficsplit.Splitter).Init(kv, alternate, trafficsplit.Options{
+		Fraction:        0.5,
+		StickyKeyGetter: trafficsplit.ContextStickyKey(userKey{}),
+	})
+	proxyz.InterceptAllMethodCalls(kv, splitter.InterceptMethodCall)
+
+	for i := 0; i < 20; i++ {
+		ctx := context.WithValue(context.Background(), userKey{}, "user"+strconv.Itoa(i))
+		kv.Set(ctx, "a", "b")
+		kv.Set(ctx, "a", "b")
+		kv.Set(ctx, "a", "b")
+	}
+
+	assert.Zero(t, splitter.Outcomes(trafficsplit.Alternate).Calls%3)
+	assert.Zero(t, splitter.Outcomes(trafficsplit.Primary).Calls%3)
+}
+
+func TestSplitterOutcomes(t *testing.T) {
+	kv := testproxy.NewKVProxy(testproxy.MapKV{})
+	splitter := new(trafficsplit.Splitter).Init(kv, testproxy.MapKV{}, trafficsplit.Options{})
+	proxyz.InterceptAllMethodCalls(kv, splitter.InterceptMethodCall)
+	kv.XxxInterceptMethodCall(testproxy.KVProxyKeys, func(proxyz.MethodCall) { panic("boom") })
+	kv.XxxInterceptMethodCall(testproxy.KVProxyGet, func(proxyz.MethodCall) { runtime.Goexit() })
+
+	assert.Panics(t, func() { kv.Keys() })
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		kv.Get(context.Background(), "foo")
+	}()
+
+	<-done
+	assert.Equal(t, trafficsplit.Outcomes{Calls: 2, Panics: 1}, splitter.Outcomes(trafficsplit.Primary))
+}
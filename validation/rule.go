@@ -0,0 +1,182 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type rule struct {
+	Text  string
+	Check func(value reflect.Value) bool
+}
+
+func parseRules(tag string) ([]rule, error) {
+	var rules []rule
+
+	for tag != "" {
+		var ruleText string
+
+		if strings.HasPrefix(tag, "regexp=") {
+			ruleText, tag = tag, ""
+		} else if i := strings.IndexByte(tag, ','); i >= 0 {
+			ruleText, tag = tag[:i], tag[i+1:]
+		} else {
+			ruleText, tag = tag, ""
+		}
+
+		check, err := parseRule(ruleText)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, rule{
+			Text:  ruleText,
+			Check: check,
+		})
+	}
+
+	return rules, nil
+}
+
+func parseRule(ruleText string) (func(reflect.Value) bool, error) {
+	name, param := ruleText, ""
+
+	if i := strings.IndexByte(ruleText, '='); i >= 0 {
+		name, param = ruleText[:i], ruleText[i+1:]
+	}
+
+	switch name {
+	case "required":
+		return checkRequired, nil
+	case "min", "max":
+		bound, err := strconv.ParseFloat(param, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %q: %v", ruleText, err)
+		}
+
+		if name == "min" {
+			return func(value reflect.Value) bool {
+				x, ok := measureValue(value)
+				return !ok || x >= bound
+			}, nil
+		}
+
+		return func(value reflect.Value) bool {
+			x, ok := measureValue(value)
+			return !ok || x <= bound
+		}, nil
+	case "len":
+		length, err := strconv.Atoi(param)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %q: %v", ruleText, err)
+		}
+
+		return func(value reflect.Value) bool {
+			value, ok := indirectValue(value)
+
+			if !ok {
+				return true
+			}
+
+			switch value.Kind() {
+			case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+				return value.Len() == length
+			default:
+				return true
+			}
+		}, nil
+	case "oneof":
+		options := strings.Fields(param)
+
+		if len(options) == 0 {
+			return nil, fmt.Errorf("invalid rule %q: no options", ruleText)
+		}
+
+		return func(value reflect.Value) bool {
+			value, ok := indirectValue(value)
+
+			if !ok {
+				return true
+			}
+
+			s := fmt.Sprint(value.Interface())
+
+			for _, option := range options {
+				if s == option {
+					return true
+				}
+			}
+
+			return false
+		}, nil
+	case "regexp":
+		regexp1, err := regexp.Compile(param)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %q: %v", ruleText, err)
+		}
+
+		return func(value reflect.Value) bool {
+			value, ok := indirectValue(value)
+
+			if !ok || value.Kind() != reflect.String {
+				return true
+			}
+
+			return regexp1.MatchString(value.String())
+		}, nil
+	default:
+		return nil, errors.New("unknown rule " + strconv.Quote(ruleText))
+	}
+}
+
+func checkRequired(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return !value.IsNil()
+	case reflect.Slice, reflect.Map:
+		return value.Len() >= 1
+	default:
+		return !value.IsZero()
+	}
+}
+
+func measureValue(value reflect.Value) (float64, bool) {
+	value, ok := indirectValue(value)
+
+	if !ok {
+		return 0, false
+	}
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return float64(value.Len()), true
+	default:
+		return 0, false
+	}
+}
+
+func indirectValue(value reflect.Value) (reflect.Value, bool) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}, false
+		}
+
+		value = value.Elem()
+	}
+
+	return value, value.IsValid()
+}
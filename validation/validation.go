@@ -0,0 +1,317 @@
+// Package validation provides an interceptor validating the arguments of
+// method calls against the `validate:"..."` tags on struct fields.
+//
+// The tag is a comma-separated list of the rules below:
+//
+//	required        the value must not be zero
+//	min=N           numbers must be >= N; strings, slices and maps must have length >= N
+//	max=N           numbers must be <= N; strings, slices and maps must have length <= N
+//	len=N           strings, slices and maps must have length == N
+//	oneof=A B C     the value, formatted by fmt.Sprint, must be one of the space-separated options
+//	regexp=PATTERN  strings must match the pattern, which consumes the rest of the tag
+//
+// Nil pointers are only checked against the rule required, non-nil pointers
+// are dereferenced. Fields of struct types carrying rules, directly or through
+// their fields, are validated recursively, and each pointer is visited once.
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/roy2220/proxyz"
+)
+
+// InterceptMethodCall is a proxyz.MethodCallInterceptor validating the
+// arguments of the method call. On failure, an *Error, or the error of
+// parsing malformed tags, is set as the trailing error result and the method
+// call will NOT be forwarded. If the method has no trailing error result, it
+// panics with the error instead.
+func InterceptMethodCall(methodCall proxyz.MethodCall) {
+	var validator validator
+
+	for i, n := 0, methodCall.NumberOfArgs(); i < n; i++ {
+		validator.ValidateValue(i, "", reflect.ValueOf(methodCall.GetArg(i)))
+	}
+
+	var err error
+
+	switch {
+	case validator.Err != nil:
+		err = validator.Err
+	case validator.Violations != nil:
+		err = &Error{
+			MethodName: methodCall.MethodName(),
+			Violations: validator.Violations,
+		}
+	default:
+		methodCall.Forward()
+		return
+	}
+
+	if !proxyz.SetError(methodCall, err) {
+		panic(err)
+	}
+}
+
+// Validate validates the given value, which is usually a struct or a pointer
+// to a struct. It returns an *Error on failure, or the error of parsing
+// malformed tags.
+func Validate(value interface{}) error {
+	var validator validator
+	validator.ValidateValue(-1, "", reflect.ValueOf(value))
+
+	if validator.Err != nil {
+		return validator.Err
+	}
+
+	if validator.Violations == nil {
+		return nil
+	}
+
+	return &Error{Violations: validator.Violations}
+}
+
+// Error represents a validation failure.
+type Error struct {
+	MethodName string
+	Violations []Violation
+}
+
+// Error implements error.Error.
+func (e *Error) Error() string {
+	var buffer strings.Builder
+	buffer.WriteString("validation: invalid arguments")
+
+	if e.MethodName != "" {
+		fmt.Fprintf(&buffer, "; methodName=%q", e.MethodName)
+	}
+
+	for i := range e.Violations {
+		if i == 0 {
+			buffer.WriteString(": ")
+		} else {
+			buffer.WriteString(", ")
+		}
+
+		buffer.WriteString(e.Violations[i].String())
+	}
+
+	return buffer.String()
+}
+
+// Violation represents a violation of a rule.
+type Violation struct {
+	// ArgIndex is the index of the argument violating the rule, or -1 if
+	// the value is validated by Validate.
+	ArgIndex int
+
+	// FieldPath is the dot-separated path to the field violating the rule.
+	FieldPath string
+
+	// Rule is the rule violated, e.g. "min=1".
+	Rule string
+}
+
+// String returns the representation of the violation.
+func (v *Violation) String() string {
+	var s string
+
+	if v.ArgIndex >= 0 {
+		s = "arg" + strconv.Itoa(v.ArgIndex)
+	}
+
+	if v.FieldPath != "" {
+		if s != "" {
+			s += "."
+		}
+
+		s += v.FieldPath
+	}
+
+	return s + " violates " + v.Rule
+}
+
+type validator struct {
+	Violations      []Violation
+	Err             error
+	visitedPointers map[visitedPointer]struct{}
+}
+
+type visitedPointer struct {
+	Type    reflect.Type
+	Address uintptr
+}
+
+func (v *validator) ValidateValue(argIndex int, fieldPath string, value reflect.Value) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+
+		if value.Kind() == reflect.Ptr {
+			pointer := visitedPointer{value.Type(), value.Pointer()}
+
+			if _, ok := v.visitedPointers[pointer]; ok {
+				return
+			}
+
+			if v.visitedPointers == nil {
+				v.visitedPointers = make(map[visitedPointer]struct{})
+			}
+
+			v.visitedPointers[pointer] = struct{}{}
+		}
+
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return
+	}
+
+	structInfo := getStructInfo(value.Type())
+
+	if structInfo.Err != nil {
+		if v.Err == nil {
+			v.Err = structInfo.Err
+		}
+
+		return
+	}
+
+	if !structInfo.IsValidated {
+		return
+	}
+
+	for _, field := range structInfo.Fields {
+		fieldValue := value.Field(field.Index)
+		fieldPath2 := field.Name
+
+		if fieldPath != "" {
+			fieldPath2 = fieldPath + "." + field.Name
+		}
+
+		for _, rule := range field.Rules {
+			if !rule.Check(fieldValue) {
+				v.Violations = append(v.Violations, Violation{
+					ArgIndex:  argIndex,
+					FieldPath: fieldPath2,
+					Rule:      rule.Text,
+				})
+			}
+		}
+
+		v.ValidateValue(argIndex, fieldPath2, fieldValue)
+	}
+}
+
+type structInfo struct {
+	Fields []field
+
+	// IsValidated indicates whether the struct type, or any struct type
+	// reachable through its fields other than interfaces, carries rules.
+	IsValidated bool
+
+	// Err is the error of parsing the tags of the struct type or any struct
+	// type reachable through its fields.
+	Err error
+}
+
+type field struct {
+	Index int
+	Name  string
+	Type  reflect.Type
+	Rules []rule
+}
+
+var type2StructInfo sync.Map
+
+func getStructInfo(structType reflect.Type) *structInfo {
+	if value, ok := type2StructInfo.Load(structType); ok {
+		return value.(*structInfo)
+	}
+
+	structInfo := new(structInfo)
+	structInfo.Fields, structInfo.Err = parseFields(structType)
+
+	if structInfo.Err == nil {
+		structInfo.IsValidated, structInfo.Err = isValidated(structType, make(map[reflect.Type]struct{}))
+	}
+
+	type2StructInfo.Store(structType, structInfo)
+	return structInfo
+}
+
+// isValidated reports whether the given struct type, or any struct type
+// reachable through its fields, carries rules. Fields of interface types are
+// not followed.
+func isValidated(structType reflect.Type, visitedTypes map[reflect.Type]struct{}) (bool, error) {
+	if _, ok := visitedTypes[structType]; ok {
+		return false, nil
+	}
+
+	visitedTypes[structType] = struct{}{}
+	fields, err := parseFields(structType)
+
+	if err != nil {
+		return false, err
+	}
+
+	result := false
+
+	for _, field := range fields {
+		if len(field.Rules) >= 1 {
+			result = true
+		}
+
+		fieldType := field.Type
+
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if fieldType.Kind() == reflect.Struct {
+			ok, err := isValidated(fieldType, visitedTypes)
+
+			if err != nil {
+				return false, err
+			}
+
+			result = result || ok
+		}
+	}
+
+	return result, nil
+}
+
+func parseFields(structType reflect.Type) ([]field, error) {
+	var fields []field
+
+	for i, n := 0, structType.NumField(); i < n; i++ {
+		structField := structType.Field(i)
+
+		if structField.PkgPath != "" {
+			continue
+		}
+
+		rules, err := parseRules(structField.Tag.Get("validate"))
+
+		if err != nil {
+			return nil, fmt.Errorf("validation: invalid tag; structType=%q fieldName=%q: %v",
+				structType.String(), structField.Name, err)
+		}
+
+		fields = append(fields, field{
+			Index: i,
+			Name:  structField.Name,
+			Type:  structField.Type,
+			Rules: rules,
+		})
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,98 @@
+package validation_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/roy2220/proxyz"
+	"github.com/roy2220/proxyz/internal/testproxy"
+	"github.com/roy2220/proxyz/validation"
+)
+
+func TestInterceptMethodCall(t *testing.T) {
+	as := testproxy.NewAccountStoreProxy(testproxy.MapAccountStore{})
+	proxyz.InterceptAllMethodCalls(as, validation.InterceptMethodCall)
+	ctx := context.Background()
+
+	err := as.CreateAccount(ctx, &testproxy.Account{Name: "roy", Role: "admin"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, as.CountAccounts())
+
+	err = as.CreateAccount(ctx, &testproxy.Account{Name: "Roy", Role: "root", Tags: []string{"a", "b", "c"}})
+	assert.EqualError(t, err, `validation: invalid arguments; methodName="CreateAccount": `+
+		"arg1.Name violates regexp=^[a-z]+$, arg1.Role violates oneof=admin user, arg1.Tags violates max=2")
+	assert.Equal(t, 1, as.CountAccounts())
+
+	err = as.CreateAccount(ctx, &testproxy.Account{Role: "user"})
+	if assert.IsType(t, (*validation.Error)(nil), err) {
+		violations := err.(*validation.Error).Violations
+		assert.Equal(t, []validation.Violation{
+			{ArgIndex: 1, FieldPath: "Name", Rule: "required"},
+			{ArgIndex: 1, FieldPath: "Name", Rule: "min=3"},
+			{ArgIndex: 1, FieldPath: "Name", Rule: "regexp=^[a-z]+$"},
+		}, violations)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	type Inner struct {
+		Code  int     `validate:"min=100,max=599"`
+		Ratio float64 `validate:"max=1"`
+		Kind  *string `validate:"required,len=3"`
+	}
+
+	type Outer struct {
+		ID     string `validate:"len=4"`
+		Inner  Inner
+		Inner2 *Inner
+		Labels map[string]string `validate:"required"`
+		Level  int               `validate:"oneof=1 2 3"`
+	}
+
+	kind := "abc"
+	assert.NoError(t, validation.Validate(&Outer{
+		ID:     "abcd",
+		Inner:  Inner{Code: 200, Kind: &kind},
+		Labels: map[string]string{"a": "b"},
+		Level:  2,
+	}))
+
+	kind2 := "ab"
+	err := validation.Validate(Outer{
+		ID:     "abc",
+		Inner:  Inner{Code: 600, Ratio: 1.5},
+		Inner2: &Inner{Code: 99, Kind: &kind2},
+		Level:  4,
+	})
+	assert.EqualError(t, err, "validation: invalid arguments: ID violates len=4, Inner.Code violates max=599, "+
+		"Inner.Ratio violates max=1, Inner.Kind violates required, Inner2.Code violates min=100, "+
+		"Inner2.Kind violates len=3, Labels violates required, Level violates oneof=1 2 3")
+
+	err = validation.Validate(struct {
+		X int `validate:"foo"`
+	}{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "validation: invalid tag")
+	}
+
+	assert.NotPanics(t, func() { validation.Validate(&http.Request{}) })
+}
+
+func TestValidateCycle(t *testing.T) {
+	type Node struct {
+		Name string `validate:"required"`
+		Next *Node
+	}
+
+	n := &Node{}
+	n.Next = n
+	err := validation.Validate(n)
+	if assert.IsType(t, (*validation.Error)(nil), err) {
+		assert.Equal(t, []validation.Violation{
+			{ArgIndex: -1, FieldPath: "Name", Rule: "required"},
+		}, err.(*validation.Error).Violations)
+	}
+}
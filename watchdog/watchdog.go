@@ -0,0 +1,245 @@
+// Package watchdog provides an interceptor tracking in-flight method calls
+// and reporting the ones exceeding a threshold, e.g. the stuck ones.
+package watchdog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/roy2220/proxyz"
+	"github.com/roy2220/proxyz/internal/goroutine"
+	"github.com/roy2220/proxyz/internal/reporting"
+)
+
+// Call represents an in-flight method call.
+type Call struct {
+	CallID         uint64
+	UnderlyingType string
+	MethodName     string
+	Args           []string
+	StartTime      time.Time
+	GoroutineID    int64
+}
+
+// Report represents a report of a slow method call.
+type Report struct {
+	Call
+
+	// Duration is the duration since the method call started.
+	Duration time.Duration
+
+	// Stack is the stack of the goroutine making the method call, if found.
+	Stack string
+
+	// AllStacks are the stacks of all the goroutines.
+	AllStacks string
+}
+
+// WriteTo writes the report in text, including the stacks of all the
+// goroutines, to the given writer.
+func (r *Report) WriteTo(writer io.Writer) (int64, error) {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "watchdog: slow method call; callID=%d method=%s.%s duration=%v goroutineID=%d\n",
+		r.CallID, r.UnderlyingType, r.MethodName, r.Duration, r.GoroutineID)
+
+	reporting.WriteArgs(&buffer, "\t", r.Args)
+
+	if r.Stack != "" {
+		buffer.WriteString(r.Stack)
+		buffer.WriteByte('\n')
+	}
+
+	if r.AllStacks != "" {
+		buffer.WriteString("all goroutines:\n")
+		buffer.WriteString(r.AllStacks)
+		buffer.WriteByte('\n')
+	}
+
+	return buffer.WriteTo(writer)
+}
+
+// Options represents options for watchdogs.
+type Options struct {
+	// Threshold is the duration beyond which method calls are reported.
+	Threshold time.Duration
+
+	// CheckInterval is the interval between checks for slow method calls.
+	// If not specified, a quarter of Threshold will be used.
+	CheckInterval time.Duration
+
+	// Reporter reports slow method calls, each method call is reported at
+	// most once. If not specified, the reports will be written to stderr.
+	Reporter func(report *Report)
+}
+
+// Watchdog tracks in-flight method calls and reports the slow ones.
+type Watchdog struct {
+	options      Options
+	mutex        sync.Mutex
+	callID2Entry map[uint64]*callEntry
+	stop         chan struct{}
+	done         chan struct{}
+}
+
+type callEntry struct {
+	Call
+	IsReported bool
+}
+
+// Init initializes the watchdog with the given options and returns it.
+func (w *Watchdog) Init(options Options) *Watchdog {
+	if options.CheckInterval <= 0 {
+		options.CheckInterval = options.Threshold / 4
+
+		if options.CheckInterval <= 0 {
+			options.CheckInterval = time.Millisecond
+		}
+	}
+
+	if options.Reporter == nil {
+		options.Reporter = func(report *Report) { reporting.WriteToStderr(report) }
+	}
+
+	w.options = options
+	w.callID2Entry = make(map[uint64]*callEntry)
+	return w
+}
+
+// Start starts checking for slow method calls in the background. It does
+// nothing if already started.
+func (w *Watchdog) Start() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.stop != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	w.stop = stop
+	w.done = done
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(w.options.CheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				w.Check()
+			}
+		}
+	}()
+}
+
+// Stop stops checking for slow method calls. It does nothing if not started.
+func (w *Watchdog) Stop() {
+	w.mutex.Lock()
+	stop, done := w.stop, w.done
+	w.stop, w.done = nil, nil
+	w.mutex.Unlock()
+
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	<-done
+}
+
+// Watch adds an interceptor to the given proxy to track the calls to all the
+// methods of the proxy.
+func (w *Watchdog) Watch(proxy proxyz.Proxy) {
+	proxyz.InterceptAllMethodCalls(proxy, w.NewMethodCallInterceptor(proxy.XxxUnderlyingType()))
+}
+
+// NewMethodCallInterceptor returns an interceptor tracking the method calls of
+// the given underlying type.
+func (w *Watchdog) NewMethodCallInterceptor(underlyingType string) proxyz.MethodCallInterceptor {
+	return func(methodCall proxyz.MethodCall) {
+		entry := callEntry{Call: Call{
+			CallID:         methodCall.CallID(),
+			UnderlyingType: underlyingType,
+			MethodName:     methodCall.MethodName(),
+			Args:           proxyz.FormatArgs(methodCall),
+			StartTime:      methodCall.StartTime(),
+			GoroutineID:    goroutine.CurrentID(),
+		}}
+
+		w.mutex.Lock()
+		w.callID2Entry[entry.CallID] = &entry
+		w.mutex.Unlock()
+
+		defer func() {
+			w.mutex.Lock()
+			delete(w.callID2Entry, entry.CallID)
+			w.mutex.Unlock()
+		}()
+
+		methodCall.Forward()
+	}
+}
+
+// InFlightCalls returns the in-flight method calls, in the order they started.
+func (w *Watchdog) InFlightCalls() []Call {
+	w.mutex.Lock()
+	calls := make([]Call, 0, len(w.callID2Entry))
+
+	for _, entry := range w.callID2Entry {
+		calls = append(calls, entry.Call)
+	}
+
+	w.mutex.Unlock()
+
+	sort.Slice(calls, func(i, j int) bool {
+		return calls[i].CallID < calls[j].CallID
+	})
+
+	return calls
+}
+
+// Check reports the slow method calls not reported yet. It is called
+// periodically after Start.
+func (w *Watchdog) Check() {
+	now := time.Now()
+	var calls []Call
+	w.mutex.Lock()
+
+	for _, entry := range w.callID2Entry {
+		if !entry.IsReported && now.Sub(entry.StartTime) >= w.options.Threshold {
+			entry.IsReported = true
+			calls = append(calls, entry.Call)
+		}
+	}
+
+	w.mutex.Unlock()
+
+	if len(calls) == 0 {
+		return
+	}
+
+	sort.Slice(calls, func(i, j int) bool {
+		return calls[i].CallID < calls[j].CallID
+	})
+
+	allStacks := goroutine.AllStacks()
+
+	for i := range calls {
+		call := &calls[i]
+
+		w.options.Reporter(&Report{
+			Call:      *call,
+			Duration:  now.Sub(call.StartTime),
+			Stack:     goroutine.FindStack(allStacks, call.GoroutineID),
+			AllStacks: allStacks,
+		})
+	}
+}
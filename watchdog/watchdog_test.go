@@ -0,0 +1,83 @@
+package watchdog_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roy2220/proxyz"
+	"github.com/roy2220/proxyz/internal/testproxy"
+	"github.com/roy2220/proxyz/watchdog"
+)
+
+func TestWatchdog(t *testing.T) {
+	reports := make(chan *watchdog.Report, 10)
+	w := new(watchdog.Watchdog).Init(watchdog.Options{
+		Threshold: 50 * time.Millisecond,
+		Reporter:  func(report *watchdog.Report) { reports <- report },
+	})
+	w.Start()
+	defer w.Stop()
+	kv := testproxy.NewKVProxy(testproxy.MapKV{})
+	w.Watch(kv)
+	unblock := make(chan struct{})
+	kv.XxxInterceptMethodCall(testproxy.KVProxySet, func(mc proxyz.MethodCall) {
+		<-unblock
+		mc.Forward()
+	})
+	done := make(chan struct{})
+
+	go func() {
+		kv.Set(context.Background(), "foo", "bar")
+		close(done)
+	}()
+
+	assert.Empty(t, kv.Keys())
+	require.Eventually(t, func() bool {
+		return len(w.InFlightCalls()) == 1
+	}, time.Second, time.Millisecond)
+	calls := w.InFlightCalls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "Set", calls[0].MethodName)
+	assert.Equal(t, []string{"<context>", "foo", "bar"}, calls[0].Args)
+
+	var report *watchdog.Report
+
+	select {
+	case report = <-reports:
+	case <-time.After(time.Second):
+		t.Fatal("no report")
+	}
+
+	assert.Equal(t, calls[0].CallID, report.CallID)
+	assert.True(t, report.Duration >= 50*time.Millisecond)
+	assert.Contains(t, report.Stack, "watchdog_test.TestWatchdog")
+	assert.Contains(t, report.AllStacks, report.Stack)
+	var buffer bytes.Buffer
+	_, err := report.WriteTo(&buffer)
+	assert.NoError(t, err)
+	assert.Contains(t, buffer.String(), "method=github.com/roy2220/proxyz/internal/testproxy.KV.Set")
+	assert.Contains(t, buffer.String(), "arg1: foo")
+	assert.Contains(t, buffer.String(), "all goroutines:\n"+report.AllStacks)
+
+	close(unblock)
+	<-done
+	assert.Empty(t, w.InFlightCalls())
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, reports)
+}
+
+func TestWatchdogStartStop(t *testing.T) {
+	w := new(watchdog.Watchdog).Init(watchdog.Options{Threshold: time.Second})
+	assert.NotPanics(t, w.Stop)
+	w.Start()
+	w.Start()
+	assert.NotPanics(t, w.Stop)
+	assert.NotPanics(t, w.Stop)
+	w.Start()
+	w.Stop()
+}